@@ -0,0 +1,5 @@
+// Package pb contains the protobuf messages & gRPC services generated from the protobuf
+// definitions in this directory.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative provider.proto pokemon.proto
@@ -0,0 +1,99 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// PokemonServiceClient is the client API for PokemonService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PokemonServiceClient interface {
+	// GetPokemon gets a pokemon based on its name.
+	GetPokemon(ctx context.Context, in *GetPokemonRequest, opts ...grpc.CallOption) (*Pokemon, error)
+}
+
+type pokemonServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPokemonServiceClient(cc grpc.ClientConnInterface) PokemonServiceClient {
+	return &pokemonServiceClient{cc}
+}
+
+func (c *pokemonServiceClient) GetPokemon(ctx context.Context, in *GetPokemonRequest, opts ...grpc.CallOption) (*Pokemon, error) {
+	out := new(Pokemon)
+	err := c.cc.Invoke(ctx, "/skeleton.v1.PokemonService/GetPokemon", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PokemonServiceServer is the server API for PokemonService service.
+// All implementations must embed UnimplementedPokemonServiceServer
+// for forward compatibility
+type PokemonServiceServer interface {
+	// GetPokemon gets a pokemon based on its name.
+	GetPokemon(context.Context, *GetPokemonRequest) (*Pokemon, error)
+	mustEmbedUnimplementedPokemonServiceServer()
+}
+
+// UnimplementedPokemonServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPokemonServiceServer struct {
+}
+
+func (UnimplementedPokemonServiceServer) GetPokemon(context.Context, *GetPokemonRequest) (*Pokemon, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPokemon not implemented")
+}
+func (UnimplementedPokemonServiceServer) mustEmbedUnimplementedPokemonServiceServer() {}
+
+// UnsafePokemonServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PokemonServiceServer will
+// result in compilation errors.
+type UnsafePokemonServiceServer interface {
+	mustEmbedUnimplementedPokemonServiceServer()
+}
+
+func RegisterPokemonServiceServer(s grpc.ServiceRegistrar, srv PokemonServiceServer) {
+	s.RegisterService(&_PokemonService_serviceDesc, srv)
+}
+
+func _PokemonService_GetPokemon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPokemonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).GetPokemon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/skeleton.v1.PokemonService/GetPokemon",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).GetPokemon(ctx, req.(*GetPokemonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _PokemonService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "skeleton.v1.PokemonService",
+	HandlerType: (*PokemonServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPokemon",
+			Handler:    _PokemonService_GetPokemon_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pokemon.proto",
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"time"
 
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server/http"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server/prometheus"
@@ -23,5 +25,14 @@ func main() {
 		},
 	)
 
-	server.RunServersGracefully(time.Second*5, promServer, httpServer)
+	ctx, cancel := server.NotifyContext(context.Background())
+	defer cancel()
+
+	lifecycle := server.NewLifecycle(server.LifecycleConfig{StopTimeout: 5 * time.Second})
+	lifecycle.AppendServer("prometheus", promServer)
+	lifecycle.AppendServer("http", httpServer)
+
+	if err := lifecycle.Run(ctx); err != nil {
+		log.Fatal(err, "Failed running servers")
+	}
 }
@@ -1,45 +1,184 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/satriajidam/go-gin-skeleton/api/proto/v1"
 	"github.com/satriajidam/go-gin-skeleton/internal/config"
 	"github.com/satriajidam/go-gin-skeleton/internal/service/api"
 	"github.com/satriajidam/go-gin-skeleton/internal/service/client/pokeapi"
+	"github.com/satriajidam/go-gin-skeleton/internal/service/domain"
 	"github.com/satriajidam/go-gin-skeleton/internal/service/pokemon"
 	"github.com/satriajidam/go-gin-skeleton/internal/service/provider"
 	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+	"github.com/satriajidam/go-gin-skeleton/pkg/cli"
 	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
-	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql/mysql"
+	"github.com/satriajidam/go-gin-skeleton/pkg/health"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/retry"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/admin"
+	grpcserver "github.com/satriajidam/go-gin-skeleton/pkg/server/grpc"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/bodylimit"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/httpcache"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/idempotency"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/ratelimit"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/requestid"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/sqlsession"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/tenant"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/routelimit"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server/prometheus"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/stoptimeout"
+
+	// Register all supported SQL database drivers.
+	_ "github.com/satriajidam/go-gin-skeleton/pkg/database/sql/mssql"
+	_ "github.com/satriajidam/go-gin-skeleton/pkg/database/sql/mysql"
+	_ "github.com/satriajidam/go-gin-skeleton/pkg/database/sql/postgres"
+	_ "github.com/satriajidam/go-gin-skeleton/pkg/database/sql/sqlite"
 )
 
 func main() {
-	cfg := config.Get()
+	app := &cli.App{
+		Name:    "server",
+		Default: "serve",
+		Commands: []cli.Command{
+			{Name: "serve", Usage: "Start the REST API server (default)", Run: serve},
+			{Name: "migrate", Usage: "Manage SQL database schema migrations", Run: runMigrate},
+			{Name: "seed", Usage: "Load fixtures into the SQL database & Redis", Run: runSeed},
+			{Name: "secrets", Usage: "Manage the encrypted secrets file", Run: runSecrets},
+		},
+	}
 
-	dbconn, err := mysql.NewConnection(sql.DBConfig{
-		Host:          cfg.MySQLHost,
-		Port:          cfg.MySQLPort,
-		Database:      cfg.MySQLDatabase,
-		Username:      cfg.MySQLUsername,
-		Password:      cfg.MySQLPassword,
-		Params:        cfg.MySQLParams,
-		MaxIdleConns:  cfg.MySQLMaxIdleConns,
-		MaxOpenConns:  cfg.MySQLMaxOpenConns,
-		SingularTable: cfg.MySQLSingularTable,
-		DebugMode:     cfg.MySQLDebugMode,
-	})
-	defer func() {
-		err := dbconn.Close()
-		if err != nil {
-			panic(err)
+	loader, args, err := config.ParseFlags(app.Name, os.Args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
+			fmt.Fprint(os.Stdout, app.Usage())
+			return
 		}
-	}()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Init(loader)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := log.Configure(cfg.LogLevel, cfg.LogAsJSON); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	log.Stdout().Debug().Timestamp().Interface("config", cfg).Msg("Configurations loaded")
+
+	if err := app.Run(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newDBConnection(ctx context.Context, cfg *config.Config) (*sql.Connection, error) {
+	var conf sql.DBConfig
+
+	switch strings.ToLower(cfg.DBDriver) {
+	case "mysql":
+		conf = sql.DBConfig{
+			Host:          cfg.MySQLHost,
+			Port:          cfg.MySQLPort,
+			Database:      cfg.MySQLDatabase,
+			Username:      cfg.MySQLUsername,
+			Password:      cfg.MySQLPassword,
+			Params:        cfg.MySQLParams,
+			MaxIdleConns:  cfg.MySQLMaxIdleConns,
+			MaxOpenConns:  cfg.MySQLMaxOpenConns,
+			SingularTable: cfg.MySQLSingularTable,
+			DebugMode:     cfg.MySQLDebugMode,
+		}
+	case "postgres":
+		conf = sql.DBConfig{
+			Host:          cfg.PostgresHost,
+			Port:          cfg.PostgresPort,
+			Database:      cfg.PostgresDatabase,
+			Username:      cfg.PostgresUsername,
+			Password:      cfg.PostgresPassword,
+			Params:        cfg.PostgresParams,
+			MaxIdleConns:  cfg.PostgresMaxIdleConns,
+			MaxOpenConns:  cfg.PostgresMaxOpenConns,
+			SingularTable: cfg.PostgresSingularTable,
+			DebugMode:     cfg.PostgresDebugMode,
+		}
+	case "mssql":
+		conf = sql.DBConfig{
+			Host:          cfg.MSSQLHost,
+			Port:          cfg.MSSQLPort,
+			Database:      cfg.MSSQLDatabase,
+			Username:      cfg.MSSQLUsername,
+			Password:      cfg.MSSQLPassword,
+			Params:        cfg.MSSQLParams,
+			MaxIdleConns:  cfg.MSSQLMaxIdleConns,
+			MaxOpenConns:  cfg.MSSQLMaxOpenConns,
+			SingularTable: cfg.MSSQLSingularTable,
+			DebugMode:     cfg.MSSQLDebugMode,
+		}
+	case "sqlite":
+		conf = sql.DBConfig{
+			Database:      cfg.SQLiteDatabase,
+			MaxIdleConns:  cfg.SQLiteMaxIdleConns,
+			MaxOpenConns:  cfg.SQLiteMaxOpenConns,
+			SingularTable: cfg.SQLiteSingularTable,
+			DebugMode:     cfg.SQLiteDebugMode,
+		}
+	}
+
+	policy, err := sql.ParseReplicaPolicy(cfg.DBReplicaPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.Retry = retry.Config{
+		MaxWait:         cfg.DBConnectMaxWait,
+		InitialInterval: cfg.ConnectRetryInitialInterval,
+		MaxInterval:     cfg.ConnectRetryMaxInterval,
+	}
+
+	conf.Logger = &sql.LoggerConfig{
+		LogQueries:         conf.DebugMode,
+		SlowQueryThreshold: cfg.DBSlowQueryThreshold,
+		SampleRate:         cfg.DBLogSampleRate,
+		RequestIDKey:       requestid.ContextKey,
+	}
+
+	conf.ReplicaConfig = sql.ReplicaConfig{
+		Policy:               policy,
+		ReadYourWritesWindow: cfg.DBReadYourWritesWindow,
+		CheckInterval:        cfg.DBReplicaCheckInterval,
+	}
+
+	for _, address := range cfg.DBReplicas {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica address %s: %v", address, err)
+		}
+		replica := conf
+		replica.Host, replica.Port, replica.Replicas = host, port, nil
+		conf.Replicas = append(conf.Replicas, replica)
 	}
 
-	redisconn, err := redis.NewConnection(redis.RedisConfig{
+	return sql.Open(ctx, cfg.DBDriver, conf)
+}
+
+func newRedisConnection(ctx context.Context, cfg *config.Config) (*redis.Connection, error) {
+	return redis.NewConnection(ctx, redis.RedisConfig{
 		Host:      cfg.RedisHost,
 		Port:      cfg.RedisPort,
 		Username:  cfg.RedisUsername,
@@ -47,15 +186,99 @@ func main() {
 		Namespace: cfg.RedisNamespace,
 		DBNumber:  cfg.RedisDBNumber,
 		DebugMode: cfg.RedisDebugMode,
+		Retry: retry.Config{
+			MaxWait:         cfg.RedisConnectMaxWait,
+			InitialInterval: cfg.ConnectRetryInitialInterval,
+			MaxInterval:     cfg.ConnectRetryMaxInterval,
+		},
 	})
-	defer func() {
-		err := redisconn.Close()
-		if err != nil {
-			panic(err)
+}
+
+// effectiveConfig reports the current configurations with their sources & without secrets.
+func effectiveConfig(ctx *gin.Context) {
+	ctx.JSON(nethttp.StatusOK, config.Get().Effective())
+}
+
+func serve(args []string) error {
+	cfg := config.Get()
+
+	// Connecting to dependencies may take a while as they're retried, so a termination
+	// signal received in the meantime cancels the startup.
+	ctx, cancel := server.NotifyContext(context.Background())
+	defer cancel()
+
+	stopTimeouts, err := stoptimeout.Parse(cfg.ShutdownStopTimeouts)
+	if err != nil {
+		return err
+	}
+
+	lifecycle := server.NewLifecycle(server.LifecycleConfig{
+		PreStopDelay:   cfg.ShutdownPreStopDelay,
+		StopTimeout:    cfg.GracefulTimeout,
+		StopTimeouts:   stopTimeouts,
+		UpgradeTimeout: cfg.UpgradeTimeout,
+	})
+
+	var dbconn *sql.Connection
+	lifecycle.Append(server.Hook{
+		Name: "sql",
+		OnStart: func(ctx context.Context) error {
+			conn, err := newDBConnection(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			dbconn = conn
+
+			if cfg.MigrationsRunOnStart {
+				migrator, err := newMigrator(cfg, dbconn)
+				if err != nil {
+					return err
+				}
+				if _, err := migrator.Up(ctx, 0); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return dbconn.Close()
+		},
+	})
+
+	var redisconn *redis.Connection
+	lifecycle.Append(server.Hook{
+		Name: "redis",
+		OnStart: func(ctx context.Context) error {
+			conn, err := newRedisConnection(ctx, cfg)
+			if err != nil && (cfg.RedisMustAvailable || ctx.Err() != nil) {
+				return err
+			}
+			redisconn = conn
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if redisconn == nil {
+				return nil
+			}
+			return redisconn.Close()
+		},
+	})
+
+	if err := lifecycle.Start(ctx); err != nil {
+		if ctx.Err() != nil {
+			log.Info("Startup interrupted")
+			return nil
 		}
-	}()
-	if err != nil && cfg.RedisMustAvailable {
-		panic(err)
+		return err
+	}
+
+	// fail stops the started dependencies when the servers can't be set up.
+	fail := func(err error) error {
+		if stopErr := lifecycle.Stop(); stopErr != nil {
+			return fmt.Errorf("%v; %v", err, stopErr)
+		}
+		return err
 	}
 
 	httpServer := http.NewServer(
@@ -67,33 +290,188 @@ func main() {
 	httpServer.CORS.AllowMethods = cfg.HTTPServerAllowMethods
 	httpServer.CORS.AllowHeaders = cfg.HTTPServerAllowHeaders
 	httpServer.CORS.MaxAge = cfg.HTTPServerMaxAge
+	httpServer.LoggerSkipPaths(cfg.HTTPServerLoggerSkipPaths...)
+	if cfg.HTTPServerTLSEnabled {
+		httpServer.TLS = &http.TLSConfig{
+			CertFile:       cfg.HTTPServerTLSCertFile,
+			KeyFile:        cfg.HTTPServerTLSKeyFile,
+			MinVersion:     cfg.HTTPServerTLSMinVersion,
+			CipherSuites:   cfg.HTTPServerTLSCipherSuites,
+			ClientCAFile:   cfg.HTTPServerTLSClientCAFile,
+			ClientAuth:     cfg.HTTPServerTLSClientAuth,
+			ReloadInterval: cfg.HTTPServerTLSReloadInterval,
+		}
+	}
+	httpServer.H2C = cfg.HTTPServerH2CEnabled
+	httpServer.ReadHeaderTimeout = cfg.HTTPServerReadHeaderTimeout
+	httpServer.ReadTimeout = cfg.HTTPServerReadTimeout
+	httpServer.WriteTimeout = cfg.HTTPServerWriteTimeout
+	httpServer.IdleTimeout = cfg.HTTPServerIdleTimeout
+	httpServer.MaxHeaderBytes = cfg.HTTPServerMaxHeaderBytes
+	if cfg.HTTPServerHTTP3Enabled {
+		httpServer.HTTP3 = &http.HTTP3Config{Port: cfg.HTTPServerHTTP3Port}
+	}
+	trustedProxies, err := listener.ParseNetworks(cfg.ProxyProtocolTrustedNetworks)
+	if err != nil {
+		return fail(err)
+	}
+	proxyProtocol := &listener.ProxyConfig{
+		TrustedNetworks: trustedProxies,
+		HeaderTimeout:   cfg.ProxyProtocolHeaderTimeout,
+	}
+	httpServer.Listen = cfg.HTTPServerListen
+	if cfg.HTTPServerProxyProtocol {
+		httpServer.ProxyProtocol = proxyProtocol
+	}
+	httpServer.AddMiddleware(sqlsession.New())
+	if cfg.HTTPServerEnablePredefinedRoutes {
+		httpServer.GET("/_/config", false, effectiveConfig)
+	}
 
-	providerRepository := provider.NewRepository(dbconn, true)
+	httpServer.Health = health.NewRegistry(cfg.HealthCheckCacheTTL, cfg.HealthCheckTimeout)
+	httpServer.Health.Register("sql", dbconn, true)
+	httpServer.Health.Register("redis", redisconn, cfg.RedisMustAvailable)
+
+	providerRepository := provider.NewRepository(dbconn, cfg.DBAutoMigrate)
 	providerCache := provider.NewCache(redisconn)
 	providerService := provider.NewService(providerRepository, providerCache)
 	providerHTTPHandler := api.NewProviderHTTPHandler(providerService)
 
 	pokeapiClient := pokeapi.NewClient(cfg.PokeAPIAddressV2, cfg.PokeAPITimeout)
+	httpServer.Health.Register("pokeapi", pokeapiClient, false)
 	pokemonService := pokemon.NewService(pokeapiClient)
 	pokemonHTTPHandler := api.NewPokemonHTTPHandler(pokemonService)
 
 	v1 := httpServer.Group("/v1")
+	v1Write := httpServer.Group("/v1")
+
+	// The body limit runs in the route groups, after the logger, metrics & CORS middlewares
+	// of the server, so rejected requests are logged, measured & get CORS headers.
+	routeBodyLimits, err := routelimit.Parse(cfg.HTTPServerRouteMaxBodyBytes)
+	if err != nil {
+		return fail(err)
+	}
+	bodyLimit := bodylimit.New(bodylimit.Config{
+		Limit:  int64(cfg.HTTPServerMaxBodyBytes),
+		Routes: routeBodyLimits,
+	})
+	v1.Use(bodyLimit)
+	v1Write.Use(bodyLimit)
+
+	var tenantConfig *tenant.Config
+	if cfg.TenantEnabled {
+		resolvers := []tenant.Resolver{}
+		for _, name := range cfg.TenantResolvers {
+			var arg string
+			switch strings.ToLower(name) {
+			case "header":
+				arg = cfg.TenantHeader
+			case "jwt":
+				arg = cfg.TenantJWTClaim
+			case "subdomain":
+				arg = cfg.TenantBaseDomain
+			}
+			resolver, err := tenant.ParseResolver(name, arg, []byte(cfg.TenantJWTSecret))
+			if err != nil {
+				return fail(err)
+			}
+			resolvers = append(resolvers, resolver)
+		}
+
+		tenantConfig = &tenant.Config{
+			Resolvers:     resolvers,
+			Required:      cfg.TenantRequired,
+			DefaultTenant: cfg.TenantDefault,
+			WithTenant:    domain.WithTenant,
+		}
+		tenantMiddleware := tenant.New(*tenantConfig)
+
+		v1.Use(tenantMiddleware)
+		v1Write.Use(tenantMiddleware)
+	}
+
+	var rateLimitRule, writeRateLimitRule *ratelimit.DynamicRule
+	if cfg.HTTPServerRateLimitEnabled {
+		rule, writeRule, err := rateLimitRules(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		rateLimitRule = ratelimit.NewDynamicRule(rule)
+		writeRateLimitRule = ratelimit.NewDynamicRule(writeRule)
+
+		rateLimiter := ratelimit.New(ratelimit.Config{Redis: redisconn})
+		rateLimit := rateLimiter.DynamicHandler(rateLimitRule)
+
+		v1.Use(rateLimit)
+		v1Write.Use(rateLimit, rateLimiter.DynamicHandler(writeRateLimitRule))
+	}
+
+	v1Write.Use(idempotency.New(idempotency.Config{
+		Redis:    redisconn,
+		TTL:      cfg.HTTPServerIdempotencyTTL,
+		ScopeKey: tenant.DefaultContextKey,
+	}))
+
+	responseCache := httpcache.New(httpcache.Config{
+		Redis:    redisconn,
+		ScopeKey: tenant.DefaultContextKey,
+	})
 
 	// Provider APIs:
-	v1.POST("/provider", true, providerHTTPHandler.CreateProvider)
-	v1.PUT("/provider/:uuid", true, providerHTTPHandler.UpdateProvider)
-	v1.DELETE("/provider/:uuid", false, providerHTTPHandler.DeleteProviderByUUID)
-	v1.GET("/provider/:uuid", false, providerHTTPHandler.GetProviderByUUID)
+	v1Write.POST("/provider", true, providerHTTPHandler.CreateProvider)
+	v1Write.PUT("/provider/:uuid", true, providerHTTPHandler.UpdateProvider)
+	v1Write.DELETE("/provider/:uuid", false, providerHTTPHandler.DeleteProviderByUUID)
+	v1.GET(
+		"/provider/:uuid", false,
+		responseCache.Handler(httpcache.Rule{
+			CacheControl: cfg.HTTPServerProviderCacheControl,
+		}),
+		providerHTTPHandler.GetProviderByUUID,
+	)
 	v1.GET("/providers", false, providerHTTPHandler.GetProviders)
 
 	// Pokemon APIs:
-	v1.GET("/pokemon/:name", false, pokemonHTTPHandler.GetPokemonByName)
+	v1.GET(
+		"/pokemon/:name", false,
+		responseCache.Handler(httpcache.Rule{
+			CacheControl: cfg.HTTPServerPokemonCacheControl,
+			TTL:          cfg.HTTPServerPokemonCacheTTL,
+		}),
+		pokemonHTTPHandler.GetPokemonByName,
+	)
+
+	var grpcServer *grpcserver.Server
+	if cfg.GRPCServerEnabled {
+		grpcServer = grpcserver.NewServer(cfg.GRPCServerPort, cfg.GRPCServerEnableReflection)
+		grpcServer.Listen = cfg.GRPCServerListen
+		if cfg.GRPCServerProxyProtocol {
+			grpcServer.ProxyProtocol = proxyProtocol
+		}
+		grpcServer.Health = httpServer.Health
+		pb.RegisterProviderServiceServer(grpcServer, api.NewProviderGRPCHandler(providerService))
+		pb.RegisterPokemonServiceServer(grpcServer, api.NewPokemonGRPCHandler(pokemonService))
+	}
+
+	var adminServer *admin.Server
+	if cfg.AdminServerEnabled {
+		adminServer = admin.NewServer(cfg.AdminServerPort, cfg.AdminServerToken)
+		adminServer.Listen = cfg.AdminServerListen
+		adminServer.AddHTTPServer(httpServer)
+		adminServer.AddTrigger("flush-provider-cache", providerService.FlushCache)
+		adminServer.GET("/config", effectiveConfig)
+	}
 
 	promServer := prometheus.NewServer(
 		cfg.PrometheusServerPort,
 		cfg.PrometheusServerMetricsPath,
 	)
 
+	promServer.PoolStatsInterval = cfg.PrometheusServerPoolStatsInterval
+	promServer.Listen = cfg.PrometheusServerListen
+	if cfg.PrometheusServerProxyProtocol {
+		promServer.ProxyProtocol = proxyProtocol
+	}
+
 	promServer.Monitor(
 		&prometheus.Target{
 			HTTPServer:    httpServer,
@@ -101,6 +479,105 @@ func main() {
 			GroupedStatus: cfg.HTTPServerMonitorGroupedStatus,
 		},
 	)
+	if grpcServer != nil {
+		promServer.MonitorGRPC(grpcServer)
+		// Added after the metrics interceptors, so rejected calls are measured too.
+		if tenantConfig != nil {
+			tenantInterceptor := grpcserver.TenantConfig{Config: *tenantConfig}
+			grpcServer.AddUnaryInterceptor(grpcserver.UnaryTenant(tenantInterceptor))
+			grpcServer.AddStreamInterceptor(grpcserver.StreamTenant(tenantInterceptor))
+		}
+	}
+	promServer.MonitorSQL(dbconn)
+	promServer.MonitorRedis(redisconn)
+
+	config.Subscribe(func(old, new *config.Config) {
+		if old.LogLevel != new.LogLevel {
+			if err := log.SetLevel(new.LogLevel); err != nil {
+				log.Error(err, "Failed changing log level")
+			}
+		}
+
+		if !reflect.DeepEqual(old.HTTPServerAllowOrigins, new.HTTPServerAllowOrigins) ||
+			!reflect.DeepEqual(old.HTTPServerAllowMethods, new.HTTPServerAllowMethods) ||
+			!reflect.DeepEqual(old.HTTPServerAllowHeaders, new.HTTPServerAllowHeaders) ||
+			old.HTTPServerMaxAge != new.HTTPServerMaxAge {
+			cors := *httpServer.CORS
+			cors.AllowOrigins = new.HTTPServerAllowOrigins
+			cors.AllowMethods = new.HTTPServerAllowMethods
+			cors.AllowHeaders = new.HTTPServerAllowHeaders
+			cors.MaxAge = new.HTTPServerMaxAge
+			if err := httpServer.UpdateCORS(cors); err != nil {
+				log.Error(err, "Failed updating CORS config")
+			}
+		}
+
+		if !reflect.DeepEqual(old.HTTPServerLoggerSkipPaths, new.HTTPServerLoggerSkipPaths) {
+			httpServer.SetLoggerSkipPaths(new.HTTPServerLoggerSkipPaths...)
+		}
+
+		if rateLimitRule != nil {
+			// Rules are validated along with the configurations, so they always parse.
+			rule, writeRule, err := rateLimitRules(new)
+			if err != nil {
+				log.Error(err, "Failed updating rate limits")
+			} else {
+				rateLimitRule.Set(rule)
+				writeRateLimitRule.Set(writeRule)
+			}
+		}
+	})
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	lifecycle.Append(server.Hook{
+		Name: "config-watch",
+		OnStart: func(ctx context.Context) error {
+			go config.Watch(watchCtx, cfg.ConfigWatchInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopWatch()
+			return nil
+		},
+	})
+
+	lifecycle.AppendServer("prometheus", promServer)
+	if adminServer != nil {
+		lifecycle.AppendServer("admin", adminServer)
+	}
+	lifecycle.AppendServer("http", httpServer)
+	if grpcServer != nil {
+		lifecycle.AppendServer("grpc", grpcServer)
+	}
+
+	return lifecycle.Run(ctx)
+}
+
+// rateLimitRules creates the common rate limit rule & the one of endpoints which modify data.
+func rateLimitRules(cfg *config.Config) (ratelimit.Rule, ratelimit.Rule, error) {
+	algorithm, err := ratelimit.ParseAlgorithm(cfg.HTTPServerRateLimitAlgorithm)
+	if err != nil {
+		return ratelimit.Rule{}, ratelimit.Rule{}, err
+	}
+
+	keyFunc, err := ratelimit.ParseKeyFunc(cfg.HTTPServerRateLimitKeyBy)
+	if err != nil {
+		return ratelimit.Rule{}, ratelimit.Rule{}, err
+	}
+
+	rule := ratelimit.Rule{
+		Name:      "v1",
+		Algorithm: algorithm,
+		Limit:     cfg.HTTPServerRateLimitRequests,
+		Period:    cfg.HTTPServerRateLimitPeriod,
+		KeyFunc:   keyFunc,
+	}
+	writeRule := ratelimit.Rule{
+		Algorithm: algorithm,
+		Limit:     cfg.HTTPServerRateLimitWriteRequests,
+		Period:    cfg.HTTPServerRateLimitWritePeriod,
+		KeyFunc:   keyFunc,
+	}
 
-	server.RunServersGracefully(cfg.GracefulTimeout, promServer, httpServer)
+	return rule, writeRule, nil
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/satriajidam/go-gin-skeleton/internal/config"
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql/migrate"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server"
+)
+
+const migrateUsage = `Usage: server migrate <up|down|status|create> [arguments]
+
+  up [N]         Apply all or the next N pending migrations
+  down [N]       Revert the last N applied migrations (default 1)
+  status         List migrations and whether they are applied
+  create <name>  Create empty up & down scripts for every SQL dialect`
+
+func newMigrator(cfg *config.Config, dbconn *sql.Connection) (*migrate.Migrator, error) {
+	return migrate.New(dbconn, migrate.Config{
+		Dir:         cfg.MigrationsDir,
+		LockTimeout: cfg.MigrationsLockTimeout,
+	})
+}
+
+func parseSteps(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of migrations: %s", args[0])
+	}
+	return steps, nil
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg := config.Get()
+
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+
+		dirs := []string{}
+		for _, dialect := range []string{
+			sql.DialectMySQL, sql.DialectPostgres, sql.DialectMSSQL, sql.DialectSQLite,
+		} {
+			dir, _ := migrate.DialectDir(dialect)
+			dirs = append(dirs, filepath.Join(cfg.MigrationsDir, dir))
+		}
+
+		paths, err := migrate.Create(strings.Join(args[1:], "_"), dirs...)
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			fmt.Println(p)
+		}
+		return nil
+	}
+
+	ctx, cancel := server.NotifyContext(context.Background())
+	defer cancel()
+
+	dbconn, err := newDBConnection(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer dbconn.Close()
+
+	migrator, err := newMigrator(cfg, dbconn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		steps, err := parseSteps(args[1:])
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", len(applied))
+	case "down":
+		steps, err := parseSteps(args[1:])
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state, appliedAt := "pending", "-"
+			if s.Applied {
+				state = "applied"
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Modified {
+				state = "modified"
+			}
+			if s.Missing {
+				state = "missing"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
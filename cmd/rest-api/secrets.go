@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/satriajidam/go-gin-skeleton/internal/config"
+)
+
+const secretsUsage = `Usage: server secrets <keygen|encrypt|decrypt> [arguments]
+
+  keygen                  Print a new base64 encoded key for SECRETS_KEY
+  encrypt <plain> <file>  Encrypt a YAML file of names & secrets with SECRETS_KEY
+  decrypt <file>          Print the secrets of an encrypted file as YAML
+
+Secret configurations reference encrypted secrets by name, e.g.
+MYSQL_PASSWORD=encrypted://mysql_password with SECRETS_FILE set to the encrypted file.`
+
+func runSecrets(args []string) error {
+	if len(args) == 0 {
+		return errors.New(secretsUsage)
+	}
+
+	switch args[0] {
+	case "keygen":
+		key, err := config.GenerateSecretsKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+	case "encrypt":
+		if len(args) != 3 {
+			return errors.New(secretsUsage)
+		}
+		key, err := config.SecretsKeyFromEnv()
+		if err != nil {
+			return err
+		}
+		plain, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		encrypted, err := config.EncryptSecrets(key, plain)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(args[2], encrypted, 0600)
+	case "decrypt":
+		if len(args) != 2 {
+			return errors.New(secretsUsage)
+		}
+		key, err := config.SecretsKeyFromEnv()
+		if err != nil {
+			return err
+		}
+		encrypted, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		secrets, err := config.DecryptSecrets(key, encrypted)
+		if err != nil {
+			return err
+		}
+		for name, secret := range secrets {
+			fmt.Fprintf(os.Stdout, "%s: %q\n", name, secret)
+		}
+	default:
+		return errors.New(secretsUsage)
+	}
+
+	return nil
+}
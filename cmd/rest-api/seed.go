@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/satriajidam/go-gin-skeleton/internal/config"
+	"github.com/satriajidam/go-gin-skeleton/internal/seed"
+	"github.com/satriajidam/go-gin-skeleton/internal/service/provider"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server"
+)
+
+func runSeed(args []string) error {
+	cfg := config.Get()
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: server seed [options]\n\nOptions:")
+		flags.PrintDefaults()
+	}
+	dir := flags.String("dir", cfg.SeedFixturesDir, "directory of the fixture sets")
+	env := flags.String("env", cfg.SeedEnv, "environment fixture set loaded after the default set")
+	warmCache := flags.Bool("cache", false, "cache the seeded entities in Redis")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	fixtures, err := seed.Load(*dir, *env)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := server.NotifyContext(context.Background())
+	defer cancel()
+
+	dbconn, err := newDBConnection(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer dbconn.Close()
+
+	redisconn, err := newRedisConnection(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer redisconn.Close()
+
+	providerRepository := provider.NewRepository(dbconn, cfg.DBAutoMigrate)
+	providerCache := provider.NewCache(redisconn)
+
+	seeder := &seed.Seeder{
+		ProviderService: provider.NewService(providerRepository, providerCache),
+		ProviderCache:   providerCache,
+		WarmCache:       *warmCache,
+	}
+
+	result, err := seeder.Run(ctx, fixtures)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Seeded %d provider(s)\n", result.Providers)
+
+	return nil
+}
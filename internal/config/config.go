@@ -3,8 +3,6 @@ package config
 import (
 	"sync"
 	"time"
-
-	"github.com/kelseyhightower/envconfig"
 )
 
 // Config stores application's configurations.
@@ -12,34 +10,183 @@ type Config struct {
 	// The name of this application.
 	AppName string `envconfig:"APP_NAME" default:"skeleton-server"`
 
-	// Graceful shutdown timeout in seconds.
-	GracefulTimeout time.Duration `envconfig:"GRACEFUL_TIMEOUT" default:"5s"`
+	// Logging configurations. Log level is one of debug, info, warn, error, fatal or panic.
+	LogLevel  string `envconfig:"LOG_LEVEL" default:"info" reload:"true"`
+	LogAsJSON bool   `envconfig:"LOG_AS_JSON" default:"false"`
+
+	// How often the config file & dotenv file are checked for changes to reload the
+	// configurations tagged as reloadable. Zero only reloads them on SIGHUP.
+	ConfigWatchInterval time.Duration `envconfig:"CONFIG_WATCH_INTERVAL" default:"10s"`
+
+	// How long each component may take to stop on shutdown. It's overridden per component
+	// by entries like "http=10s", where components are sql, redis, config-watch, prometheus,
+	// http, grpc & admin. On shutdown the readiness endpoint reports 503 during the pre-stop delay
+	// before the components are stopped.
+	GracefulTimeout      time.Duration `envconfig:"GRACEFUL_TIMEOUT" default:"5s"`
+	ShutdownStopTimeouts []string      `envconfig:"SHUTDOWN_STOP_TIMEOUTS" default:""`
+	ShutdownPreStopDelay time.Duration `envconfig:"SHUTDOWN_PRE_STOP_DELAY" default:"0s"`
+
+	// How long a new process started on SIGUSR2 may take to start. It inherits the listeners
+	// of the servers, then the previous process stops accepting connections & is stopped
+	// like on shutdown, serving the accepted ones during the pre-stop delay.
+	UpgradeTimeout time.Duration `envconfig:"UPGRADE_TIMEOUT" default:"30s"`
 
 	// HTTP Server configurations.
 	HTTPServerPort                   string        `envconfig:"HTTP_SERVER_PORT" default:"80"`
 	HTTPServerEnableCORS             bool          `envconfig:"HTTP_SERVER_ENABLE_CORS" default:"true"`
 	HTTPServerEnablePredefinedRoutes bool          `envconfig:"HTTP_SERVER_ENABLE_PREDEFINED_ROUTES" default:"true"`
-	HTTPServerAllowMethods           []string      `envconfig:"HTTP_SERVER_ALLOW_METHODS" default:""`
-	HTTPServerAllowHeaders           []string      `envconfig:"HTTP_SERVER_ALLOW_HEADERS" default:""`
-	HTTPServerAllowOrigins           []string      `envconfig:"HTTP_SERVER_ALLOW_ORIGINS" default:""`
-	HTTPServerMaxAge                 time.Duration `envconfig:"HTTP_SERVER_MAX_AGE" default:""`
+	HTTPServerAllowMethods           []string      `envconfig:"HTTP_SERVER_ALLOW_METHODS" default:"" reload:"true"`
+	HTTPServerAllowHeaders           []string      `envconfig:"HTTP_SERVER_ALLOW_HEADERS" default:"" reload:"true"`
+	HTTPServerAllowOrigins           []string      `envconfig:"HTTP_SERVER_ALLOW_ORIGINS" default:"" reload:"true"`
+	HTTPServerMaxAge                 time.Duration `envconfig:"HTTP_SERVER_MAX_AGE" default:"" reload:"true"`
 	HTTPServerMonitorGroupedStatus   bool          `envconfig:"HTTP_SERVER_MONITOR_GROUPED_STATUS" default:"false"`
-	HTTPServerMonitorSkipPaths       []string      `envconfig:"HTTP_SERVER_MONITOR_SKIP_PATHS" default:"/_/health"`
+	HTTPServerMonitorSkipPaths       []string      `envconfig:"HTTP_SERVER_MONITOR_SKIP_PATHS" default:"/_/health,/_/live,/_/ready"`
+	HTTPServerLoggerSkipPaths        []string      `envconfig:"HTTP_SERVER_LOGGER_SKIP_PATHS" default:"" reload:"true"`
+
+	// HTTP Server connection limits. Zero timeouts mean no timeout.
+	HTTPServerReadHeaderTimeout time.Duration `envconfig:"HTTP_SERVER_READ_HEADER_TIMEOUT" default:"10s"`
+	HTTPServerReadTimeout       time.Duration `envconfig:"HTTP_SERVER_READ_TIMEOUT" default:"30s"`
+	HTTPServerWriteTimeout      time.Duration `envconfig:"HTTP_SERVER_WRITE_TIMEOUT" default:"30s"`
+	HTTPServerIdleTimeout       time.Duration `envconfig:"HTTP_SERVER_IDLE_TIMEOUT" default:"2m"`
+	HTTPServerMaxHeaderBytes    int           `envconfig:"HTTP_SERVER_MAX_HEADER_BYTES" default:"1048576"`
+	// Maximum request body size in bytes, -1 means no limit. It's overridden per route by
+	// entries like "POST /v1/provider=4096".
+	HTTPServerMaxBodyBytes      int      `envconfig:"HTTP_SERVER_MAX_BODY_BYTES" default:"1048576"`
+	HTTPServerRouteMaxBodyBytes []string `envconfig:"HTTP_SERVER_ROUTE_MAX_BODY_BYTES" default:""`
+
+	// HTTP Server TLS configurations. Setting the client CA file enables mutual TLS, where
+	// client auth is either require or optional. The certificate, key & CA files are
+	// reloaded when they change, checked at the reload interval.
+	HTTPServerTLSEnabled        bool          `envconfig:"HTTP_SERVER_TLS_ENABLED" default:"false"`
+	HTTPServerTLSCertFile       string        `envconfig:"HTTP_SERVER_TLS_CERT_FILE" default:""`
+	HTTPServerTLSKeyFile        string        `envconfig:"HTTP_SERVER_TLS_KEY_FILE" default:""`
+	HTTPServerTLSMinVersion     string        `envconfig:"HTTP_SERVER_TLS_MIN_VERSION" default:"1.2"`
+	HTTPServerTLSCipherSuites   []string      `envconfig:"HTTP_SERVER_TLS_CIPHER_SUITES" default:""`
+	HTTPServerTLSClientCAFile   string        `envconfig:"HTTP_SERVER_TLS_CLIENT_CA_FILE" default:""`
+	HTTPServerTLSClientAuth     string        `envconfig:"HTTP_SERVER_TLS_CLIENT_AUTH" default:"require"`
+	HTTPServerTLSReloadInterval time.Duration `envconfig:"HTTP_SERVER_TLS_RELOAD_INTERVAL" default:"1m"`
+
+	// HTTP Server protocol configurations. h2c serves HTTP/2 over cleartext connections
+	// alongside HTTP/1.1. HTTP/3 requires TLS & a binary built with the http3 tag, and
+	// listens on the UDP port, which defaults to HTTP_SERVER_PORT.
+	HTTPServerH2CEnabled   bool   `envconfig:"HTTP_SERVER_H2C_ENABLED" default:"false"`
+	HTTPServerHTTP3Enabled bool   `envconfig:"HTTP_SERVER_HTTP3_ENABLED" default:"false"`
+	HTTPServerHTTP3Port    string `envconfig:"HTTP_SERVER_HTTP3_PORT" default:""`
+
+	// Listener configurations. Listeners are specs like tcp://:8080, unix:///run/app.sock or
+	// fd://http, a socket inherited through systemd socket activation by its number or name,
+	// and default to the ports of the servers. With PROXY protocol enabled, connections from
+	// the trusted networks must start with a v1 or v2 header giving the client address. No
+	// trusted networks means every connection must start with a header.
+	HTTPServerListen              string        `envconfig:"HTTP_SERVER_LISTEN" default:""`
+	HTTPServerProxyProtocol       bool          `envconfig:"HTTP_SERVER_PROXY_PROTOCOL" default:"false"`
+	PrometheusServerListen        string        `envconfig:"PROMETHEUS_SERVER_LISTEN" default:""`
+	PrometheusServerProxyProtocol bool          `envconfig:"PROMETHEUS_SERVER_PROXY_PROTOCOL" default:"false"`
+	ProxyProtocolTrustedNetworks  []string      `envconfig:"PROXY_PROTOCOL_TRUSTED_NETWORKS" default:""`
+	ProxyProtocolHeaderTimeout    time.Duration `envconfig:"PROXY_PROTOCOL_HEADER_TIMEOUT" default:"5s"`
+
+	// How long dependency check results of the readiness endpoint are reused & how long
+	// each check may take.
+	HealthCheckCacheTTL time.Duration `envconfig:"HEALTH_CHECK_CACHE_TTL" default:"5s"`
+	HealthCheckTimeout  time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+
+	// Multi-tenancy configurations. Resolvers are tried in the order of TENANT_RESOLVERS
+	// until one finds a tenant. The header resolver trusts whatever clients send, so it
+	// can't be listed before the jwt one, which would let it override signed claims.
+	TenantEnabled    bool     `envconfig:"TENANT_ENABLED" default:"false"`
+	TenantResolvers  []string `envconfig:"TENANT_RESOLVERS" default:"header"`
+	TenantRequired   bool     `envconfig:"TENANT_REQUIRED" default:"true"`
+	TenantDefault    string   `envconfig:"TENANT_DEFAULT" default:""`
+	TenantHeader     string   `envconfig:"TENANT_HEADER" default:"X-Tenant-ID"`
+	TenantJWTClaim   string   `envconfig:"TENANT_JWT_CLAIM" default:"tenant"`
+	TenantJWTSecret  string   `envconfig:"TENANT_JWT_SECRET" default:"" secret:"true"`
+	TenantBaseDomain string   `envconfig:"TENANT_BASE_DOMAIN" default:""`
+
+	// HTTP Server rate limit configurations. Enabling or disabling rate limits requires a
+	// restart, while the rules are reloadable.
+	HTTPServerRateLimitEnabled   bool          `envconfig:"HTTP_SERVER_RATE_LIMIT_ENABLED" default:"false"`
+	HTTPServerRateLimitAlgorithm string        `envconfig:"HTTP_SERVER_RATE_LIMIT_ALGORITHM" default:"sliding-window" reload:"true"`
+	HTTPServerRateLimitKeyBy     string        `envconfig:"HTTP_SERVER_RATE_LIMIT_KEY_BY" default:"ip" reload:"true"`
+	HTTPServerRateLimitRequests  int           `envconfig:"HTTP_SERVER_RATE_LIMIT_REQUESTS" default:"100" reload:"true"`
+	HTTPServerRateLimitPeriod    time.Duration `envconfig:"HTTP_SERVER_RATE_LIMIT_PERIOD" default:"1m" reload:"true"`
+	// Rate limit for endpoints which modify data, applied on top of the common rate limit.
+	HTTPServerRateLimitWriteRequests int           `envconfig:"HTTP_SERVER_RATE_LIMIT_WRITE_REQUESTS" default:"20" reload:"true"`
+	HTTPServerRateLimitWritePeriod   time.Duration `envconfig:"HTTP_SERVER_RATE_LIMIT_WRITE_PERIOD" default:"1m" reload:"true"`
+
+	// How long responses of requests with Idempotency-Key header are kept for replays.
+	HTTPServerIdempotencyTTL time.Duration `envconfig:"HTTP_SERVER_IDEMPOTENCY_TTL" default:"24h"`
+
+	// HTTP Server response caching configurations.
+	HTTPServerProviderCacheControl string        `envconfig:"HTTP_SERVER_PROVIDER_CACHE_CONTROL" default:"private, no-cache"`
+	HTTPServerPokemonCacheControl  string        `envconfig:"HTTP_SERVER_POKEMON_CACHE_CONTROL" default:"public, max-age=3600"`
+	HTTPServerPokemonCacheTTL      time.Duration `envconfig:"HTTP_SERVER_POKEMON_CACHE_TTL" default:"1h"`
 
 	// Prometheus Server configurations.
 	PrometheusServerPort          string `envconfig:"PROMETHEUS_SERVER_PORT" default:"9180"`
 	PrometheusServerMetricsPath   string `envconfig:"PROMETHEUS_SERVER_METRICS_PATH" default:"/metrics"`
 	PrometheusServerMetricsPrefix string `envconfig:"PROMETHEUS_SERVER_METRICS_PREFIX" default:"http"`
+	// How often SQL database & Redis connection pool stats are exported.
+	PrometheusServerPoolStatsInterval time.Duration `envconfig:"PROMETHEUS_SERVER_POOL_STATS_INTERVAL" default:"15s"`
+
+	// gRPC Server configurations. The listener spec & PROXY protocol work like the HTTP
+	// server's. Reflection lets clients like grpcurl list the services.
+	GRPCServerEnabled          bool   `envconfig:"GRPC_SERVER_ENABLED" default:"false"`
+	GRPCServerPort             string `envconfig:"GRPC_SERVER_PORT" default:"9090"`
+	GRPCServerListen           string `envconfig:"GRPC_SERVER_LISTEN" default:""`
+	GRPCServerProxyProtocol    bool   `envconfig:"GRPC_SERVER_PROXY_PROTOCOL" default:"false"`
+	GRPCServerEnableReflection bool   `envconfig:"GRPC_SERVER_ENABLE_REFLECTION" default:"true"`
+
+	// Admin Server configurations. It serves pprof, the registered routes, runtime stats,
+	// the effective configurations, log level changes & cache flushes, so it must only be
+	// reachable internally. It listens on the loopback interface unless ADMIN_SERVER_LISTEN
+	// is set. Requests must send the token as a bearer token when it's set.
+	AdminServerEnabled bool   `envconfig:"ADMIN_SERVER_ENABLED" default:"false"`
+	AdminServerPort    string `envconfig:"ADMIN_SERVER_PORT" default:"9181"`
+	AdminServerListen  string `envconfig:"ADMIN_SERVER_LISTEN" default:""`
+	AdminServerToken   string `envconfig:"ADMIN_SERVER_TOKEN" default:"" secret:"true"`
+
+	// How long to keep retrying the initial connections to dependencies which aren't
+	// available yet, along with the bounds of the exponential backoff between attempts.
+	DBConnectMaxWait            time.Duration `envconfig:"DB_CONNECT_MAX_WAIT" default:"30s"`
+	RedisConnectMaxWait         time.Duration `envconfig:"REDIS_CONNECT_MAX_WAIT" default:"30s"`
+	ConnectRetryInitialInterval time.Duration `envconfig:"CONNECT_RETRY_INITIAL_INTERVAL" default:"500ms"`
+	ConnectRetryMaxInterval     time.Duration `envconfig:"CONNECT_RETRY_MAX_INTERVAL" default:"10s"`
+
+	// SQL database driver to connect to: mysql, postgres, mssql or sqlite.
+	DBDriver string `envconfig:"DB_DRIVER" default:"mysql"`
+
+	// SQL database read replica addresses in host:port format. Replicas share the
+	// credentials & settings of the primary database.
+	DBReplicas             []string      `envconfig:"DB_REPLICAS" default:""`
+	DBReplicaPolicy        string        `envconfig:"DB_REPLICA_POLICY" default:"round-robin"`
+	DBReplicaCheckInterval time.Duration `envconfig:"DB_REPLICA_CHECK_INTERVAL" default:"10s"`
+	DBReadYourWritesWindow time.Duration `envconfig:"DB_READ_YOUR_WRITES_WINDOW" default:"5s"`
+
+	// SQL query logging configurations. Queries slower than the threshold are always logged,
+	// the others at debug level in debug mode of the database, sampled by the given rate.
+	DBSlowQueryThreshold time.Duration `envconfig:"DB_SLOW_QUERY_THRESHOLD" default:"200ms"`
+	DBLogSampleRate      float64       `envconfig:"DB_LOG_SAMPLE_RATE" default:"1"`
+
+	// SQL database schema migration configurations.
+	MigrationsDir         string        `envconfig:"MIGRATIONS_DIR" default:"migrations"`
+	MigrationsLockTimeout time.Duration `envconfig:"MIGRATIONS_LOCK_TIMEOUT" default:"1m"`
+	MigrationsRunOnStart  bool          `envconfig:"MIGRATIONS_RUN_ON_START" default:"false"`
+	// Let gorm create missing tables & columns on startup. Only meant for local development.
+	DBAutoMigrate bool `envconfig:"DB_AUTO_MIGRATE" default:"false"`
+
+	// Seed data configurations. Fixtures of the default set are loaded for every environment.
+	SeedFixturesDir string `envconfig:"SEED_FIXTURES_DIR" default:"fixtures"`
+	SeedEnv         string `envconfig:"SEED_ENV" default:"dev"`
 
 	// MySQL database configurations.
 	MySQLHost     string `envconfig:"MYSQL_HOST" default:"127.0.0.1"`
 	MySQLPort     string `envconfig:"MYSQL_PORT" default:"3306"`
 	MySQLUsername string `envconfig:"MYSQL_USERNAME" default:""`
-	MySQLPassword string `envconfig:"MYSQL_PASSWORD" default:""`
+	MySQLPassword string `envconfig:"MYSQL_PASSWORD" default:"" secret:"true"`
 	MySQLDatabase string `envconfig:"MYSQL_DATABASE" default:""`
 	// List of accepted MySQL parameters: https://github.com/go-sql-driver/mysql#parameters
 	MySQLParams        string `envconfig:"MYSQL_PARAMS" default:"interpolateParams=true&charset=utf8mb4&collation=utf8mb4_general_ci&parseTime=True&loc=Local"`
-	MySQLDebugMode     bool   `envconfig:"MYSQL_DEBUG_MODE" default:"true"`
+	MySQLDebugMode     bool   `envconfig:"MYSQL_DEBUG_MODE" default:"false"`
 	MySQLMaxIdleConns  int    `envconfig:"MYSQL_MAX_IDLE_CONNS" default:"0"`
 	MySQLMaxOpenConns  int    `envconfig:"MYSQL_MAX_OPEN_CONNS" default:"0"`
 	MySQLSingularTable bool   `envconfig:"MYSQL_SINGULAR_TABLE" default:"false"`
@@ -48,11 +195,11 @@ type Config struct {
 	PostgresHost     string `envconfig:"POSTGRES_HOST" default:"127.0.0.1"`
 	PostgresPort     string `envconfig:"POSTGRES_PORT" default:"5432"`
 	PostgresUsername string `envconfig:"POSTGRES_USERNAME" default:""`
-	PostgresPassword string `envconfig:"POSTGRES_PASSWORD" default:""`
+	PostgresPassword string `envconfig:"POSTGRES_PASSWORD" default:"" secret:"true"`
 	PostgresDatabase string `envconfig:"POSTGRES_DATABASE" default:""`
 	// List of accepted PostgreSQL parameters: https://godoc.org/github.com/lib/pq#hdr-Connection_String_Parameters
-	PostgresParams        string `envocnfig:"POSTGRES_PARAMS" default:"sslmode=require&fallback_application_name=gin"`
-	PostgresDebugMode     bool   `envconfig:"POSTGRES_DEBUG_MODE" default:"true"`
+	PostgresParams        string `envconfig:"POSTGRES_PARAMS" default:"sslmode=require&fallback_application_name=gin"`
+	PostgresDebugMode     bool   `envconfig:"POSTGRES_DEBUG_MODE" default:"false"`
 	PostgresMaxIdleConns  int    `envconfig:"POSTGRES_MAX_IDLE_CONNS" default:"0"`
 	PostgresMaxOpenConns  int    `envconfig:"POSTGRES_MAX_OPEN_CONNS" default:"0"`
 	PostgresSingularTable bool   `envconfig:"POSTGRES_SINGULAR_TABLE" default:"false"`
@@ -61,27 +208,28 @@ type Config struct {
 	MSSQLHost     string `envconfig:"MSSQL_HOST" default:"127.0.0.1"`
 	MSSQLPort     string `envconfig:"MSSQL_PORT" default:"1433"`
 	MSSQLUsername string `envconfig:"MSSQL_USERNAME" default:""`
-	MSSQLPassword string `envconfig:"MSSQL_PASSWORD" default:""`
+	MSSQLPassword string `envconfig:"MSSQL_PASSWORD" default:"" secret:"true"`
 	MSSQLDatabase string `envconfig:"MSSQL_DATABASE" default:""`
 	// List of accepted Microsoft SQL Server parameters: https://github.com/denisenkom/go-mssqldb#connection-parameters-and-dsn
-	MSSQLParams        string `envocnfig:"MSSQL_PARAMS" default:"encrypt=true&app+name=gin"`
-	MSSQLDebugMode     bool   `envconfig:"MSSQL_DEBUG_MODE" default:"true"`
+	MSSQLParams        string `envconfig:"MSSQL_PARAMS" default:"encrypt=true&app+name=gin"`
+	MSSQLDebugMode     bool   `envconfig:"MSSQL_DEBUG_MODE" default:"false"`
 	MSSQLMaxIdleConns  int    `envconfig:"MSSQL_MAX_IDLE_CONNS" default:"0"`
 	MSSQLMaxOpenConns  int    `envconfig:"MSSQL_MAX_OPEN_CONNS" default:"0"`
 	MSSQLSingularTable bool   `envconfig:"MSSQL_SINGULAR_TABLE" default:"false"`
 
 	// SQLite database configurations.
 	SQLiteDatabase      string `envconfig:"SQLITE_DATABASE" default:"file:database.db?mode=memory&cache=shared"`
-	SQLiteDebugMode     bool   `envconfig:"SQLITE_DEBUG_MODE" default:"true"`
+	SQLiteDebugMode     bool   `envconfig:"SQLITE_DEBUG_MODE" default:"false"`
 	SQLiteMaxIdleConns  int    `envconfig:"SQLITE_MAX_IDLE_CONNS" default:"1"`
 	SQLiteMaxOpenConns  int    `envconfig:"SQLITE_MAX_OPEN_CONNS" default:"1"`
 	SQLiteSingularTable bool   `envconfig:"SQLITE_SINGULAR_TABLE" default:"false"`
 
-	// Redis configurations.
+	// Redis configurations. Provider writes lock their short names in Redis, so they fail
+	// while it's unavailable, even when it isn't required to be available.
 	RedisHost          string `envconfig:"REDIS_HOST" default:"127.0.0.1"`
 	RedisPort          string `envconfig:"REDIS_PORT" default:"6379"`
 	RedisUsername      string `envconfig:"REDIS_USERNAME" default:""`
-	RedisPassword      string `envconfig:"REDIS_PASSWORD" default:""`
+	RedisPassword      string `envconfig:"REDIS_PASSWORD" default:"" secret:"true"`
 	RedisNamespace     string `envconfig:"REDIS_NAMESPACE" default:""`
 	RedisDBNumber      int    `envconfig:"REDIS_DB_NUMBER" default:"0"`
 	RedisMustAvailable bool   `envconfig:"REDIS_MUST_AVAILABLE" default:"false"`
@@ -90,19 +238,46 @@ type Config struct {
 	// External dependencies.
 	PokeAPIAddressV2 string        `envconfig:"POKEAPI_ADDRESS" default:"https://pokeapi.co/api/v2"`
 	PokeAPITimeout   time.Duration `envconfig:"POKEAPI_TIMEOUT" default:"15s"`
+
+	// sources stores the source of each configuration value by key.
+	sources map[string]string
 }
 
 var (
-	once      sync.Once
+	mu        sync.RWMutex
 	singleton *Config
+	loader    *Loader
 )
 
-// Get retrieves singleton object of application configurations.
+// Init loads application configurations with the given loader & stores them as the
+// singleton returned by Get. The loader is kept to reload the configurations.
+func Init(l *Loader) (*Config, error) {
+	cfg, err := l.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	singleton = cfg
+	loader = l
+
+	return cfg, nil
+}
+
+// Get retrieves singleton object of application configurations. When they aren't loaded
+// yet, it loads them without flags & panics on invalid configurations.
 func Get() *Config {
-	once.Do(func() {
-		singleton = &Config{}
-		envconfig.MustProcess("", singleton)
-	})
+	mu.RLock()
+	cfg := singleton
+	mu.RUnlock()
+
+	if cfg == nil {
+		var err error
+		if cfg, err = Init(NewLoader()); err != nil {
+			panic(err)
+		}
+	}
 
-	return singleton
+	return cfg
 }
@@ -0,0 +1,520 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Sources of configuration values, from the lowest to the highest precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceDotEnv  = "dotenv"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+const (
+	// EnvConfigFile is the environment variable of the config file path.
+	EnvConfigFile = "CONFIG_FILE"
+	// EnvDotEnvFile is the environment variable of the dotenv file path.
+	EnvDotEnvFile = "DOTENV_FILE"
+	// DefaultDotEnvFile is the dotenv file loaded when it exists & no other is specified.
+	DefaultDotEnvFile = ".env"
+)
+
+// Loader reads configurations from layered sources. Each layer overrides the previous one:
+// defaults, YAML or TOML config file, dotenv file, environment variables & flags.
+// The keys of all sources are the environment variable names of the configurations.
+//
+// Secret configurations may be read from files by appending _FILE to their keys, e.g.
+// MYSQL_PASSWORD_FILE=/run/secrets/mysql_password, or be references to secrets resolved
+// by the secret providers.
+type Loader struct {
+	// File is the path of the YAML or TOML config file. Empty means no config file.
+	File string
+	// DotEnvFile is the path of the dotenv file. Empty means no dotenv file.
+	DotEnvFile string
+	// Flags stores the values set by command line flags.
+	Flags map[string]string
+	// Secrets stores the secret providers by the scheme of the references they resolve.
+	Secrets map[string]SecretProvider
+}
+
+// NewLoader creates a loader reading the config file & dotenv file set in the environment.
+// The default dotenv file is only read when it exists.
+func NewLoader() *Loader {
+	l := &Loader{
+		File:    os.Getenv(EnvConfigFile),
+		Flags:   map[string]string{},
+		Secrets: defaultSecretProviders(),
+	}
+
+	if path, ok := os.LookupEnv(EnvDotEnvFile); ok {
+		l.DotEnvFile = path
+	} else if _, err := os.Stat(DefaultDotEnvFile); err == nil {
+		l.DotEnvFile = DefaultDotEnvFile
+	}
+
+	return l
+}
+
+// field describes a configuration field of the Config struct. Secret fields are tagged
+// with secret:"true".
+type field struct {
+	index  int
+	key    string
+	def    string
+	secret bool
+}
+
+func fields() []field {
+	t := reflect.TypeOf(Config{})
+	fs := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := t.Field(i).Tag.Lookup("envconfig")
+		if !ok {
+			continue
+		}
+		fs = append(fs, field{
+			index:  i,
+			key:    key,
+			def:    t.Field(i).Tag.Get("default"),
+			secret: t.Field(i).Tag.Get("secret") == "true",
+		})
+	}
+	return fs
+}
+
+// flagName converts a configuration key to its flag name, e.g. HTTP_SERVER_PORT to
+// http-server-port.
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// flagValue stores a flag value in the flags of the loader.
+type flagValue struct {
+	key    string
+	flags  map[string]string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v.flags == nil {
+		return ""
+	}
+	return v.flags[v.key]
+}
+
+func (v *flagValue) Set(s string) error {
+	v.flags[v.key] = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// ParseFlags creates a loader from the command line flags placed before the command name,
+// i.e. --config, --env-file & one flag per configuration, like --http-server-port.
+// It returns the remaining arguments.
+func ParseFlags(name string, args []string) (*Loader, []string, error) {
+	l := NewLoader()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&l.File, "config", l.File, "path of the YAML or TOML config file")
+	fs.StringVar(&l.DotEnvFile, "env-file", l.DotEnvFile, "path of the dotenv file")
+
+	t := reflect.TypeOf(Config{})
+	for _, f := range fields() {
+		fs.Var(
+			&flagValue{
+				key:    f.key,
+				flags:  l.Flags,
+				isBool: t.Field(f.index).Type.Kind() == reflect.Bool,
+			},
+			flagName(f.key),
+			fmt.Sprintf("overrides %s (default %q)", f.key, f.def),
+		)
+		if f.secret {
+			fs.Var(
+				&flagValue{key: f.key + secretFileSuffix, flags: l.Flags},
+				flagName(f.key+secretFileSuffix),
+				fmt.Sprintf("overrides %s with the content of the given file", f.key),
+			)
+		}
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [flags] <command> [arguments]\n\nFlags:\n", name)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	return l, fs.Args(), nil
+}
+
+// value is a raw configuration value along with its source.
+type value struct {
+	raw    string
+	source string
+}
+
+// Load reads all configuration sources into a new Config & validates it.
+// All invalid values are reported at once in a ValidationError.
+func (l *Loader) Load() (*Config, error) {
+	cfg, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.check(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// read reads all configuration sources into a new Config without validating it, so
+// values can be adjusted first. Values which can't be decoded are reported in a
+// ValidationError.
+func (l *Loader) read() (*Config, error) {
+	errs := &ValidationError{}
+
+	values := map[string]value{}
+	known := map[string]bool{}
+	secretFiles := map[string]string{}
+	for _, f := range fields() {
+		known[f.key] = true
+		values[f.key] = value{f.def, SourceDefault}
+		if f.secret {
+			secretFiles[f.key+secretFileSuffix] = f.key
+		}
+	}
+
+	// apply sets the values of a source, turning the secret file variants of keys into
+	// file references of the secrets.
+	apply := func(source string, raws map[string]string, strict bool) {
+		for key, raw := range raws {
+			if secretKey, ok := secretFiles[key]; ok {
+				if _, ok := raws[secretKey]; ok {
+					errs.add(key, fmt.Sprintf("conflicts with %s set in the same source (%s)", secretKey, source))
+					continue
+				}
+				values[secretKey] = value{fmt.Sprintf("file://%s", raw), source}
+				continue
+			}
+			if !known[key] {
+				if strict {
+					errs.add(key, fmt.Sprintf("unknown configuration (from %s)", source))
+				}
+				continue
+			}
+			values[key] = value{raw, source}
+		}
+	}
+
+	if l.File != "" {
+		fileValues, err := readConfigFile(l.File)
+		if err != nil {
+			return nil, err
+		}
+		apply(SourceFile, fileValues, true)
+	}
+
+	if l.DotEnvFile != "" {
+		dotEnvValues, err := readDotEnvFile(l.DotEnvFile)
+		if err != nil {
+			return nil, err
+		}
+		apply(SourceDotEnv, dotEnvValues, false)
+	}
+
+	envValues := map[string]string{}
+	for key := range known {
+		if raw, ok := os.LookupEnv(key); ok {
+			envValues[key] = raw
+		}
+	}
+	for key := range secretFiles {
+		if raw, ok := os.LookupEnv(key); ok {
+			envValues[key] = raw
+		}
+	}
+	apply(SourceEnv, envValues, false)
+
+	apply(SourceFlag, l.Flags, true)
+
+	for _, f := range fields() {
+		if !f.secret {
+			continue
+		}
+		secret, err := l.resolveSecret(values[f.key].raw)
+		if err != nil {
+			// The error may not contain the secret as its reference couldn't be resolved.
+			errs.add(f.key, fmt.Sprintf("failed reading secret: %v (from %s)", err, values[f.key].source))
+			continue
+		}
+		values[f.key] = value{secret, values[f.key].source}
+	}
+
+	cfg := &Config{sources: map[string]string{}}
+	v := reflect.ValueOf(cfg).Elem()
+	for _, f := range fields() {
+		val := values[f.key]
+		cfg.sources[f.key] = val.source
+		if err := decode(v.Field(f.index), val.raw); err != nil {
+			errs.add(f.key, fmt.Sprintf("%v (from %s)", err, val.source))
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return cfg, nil
+}
+
+// decode parses a raw value into a field the same way envconfig does.
+func decode(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		if raw == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(i))
+	case reflect.Float64:
+		if raw == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		list := []string{}
+		if raw != "" {
+			for _, item := range strings.Split(raw, ",") {
+				list = append(list, strings.TrimSpace(item))
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported configuration type %s", v.Type())
+	}
+
+	return nil
+}
+
+// readConfigFile reads a YAML or TOML config file into raw values. Nested keys are joined
+// with underscores & lists are joined with commas, so these are the same:
+//
+//	http_server_port: 8080
+//	http:
+//	  server:
+//	    port: 8080
+func readConfigFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &data)
+	case ".toml":
+		err = toml.Unmarshal(b, &data)
+	default:
+		return nil, fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", data, values)
+
+	return values, nil
+}
+
+func flatten(prefix string, data interface{}, values map[string]string) {
+	key := func(k interface{}) string {
+		name := strings.ToUpper(fmt.Sprint(k))
+		name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+		if prefix == "" {
+			return name
+		}
+		return fmt.Sprintf("%s_%s", prefix, name)
+	}
+
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			flatten(key(k), v, values)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range d {
+			flatten(key(k), v, values)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(d))
+		for _, item := range d {
+			items = append(items, fmt.Sprint(item))
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+		values[prefix] = ""
+	default:
+		values[prefix] = fmt.Sprint(d)
+	}
+}
+
+// readDotEnvFile reads KEY=VALUE lines of a dotenv file. Lines may start with "export"
+// & values may be quoted.
+func readDotEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		i := strings.Index(line, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid dotenv file %s: line %d isn't KEY=VALUE", path, n)
+		}
+
+		key, raw := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		raw, err := unquoteDotEnvValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dotenv file %s: line %d: %v", path, n, err)
+		}
+
+		values[key] = raw
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// unquoteDotEnvValue removes the quotes of a value or the comment following an unquoted
+// value. Escape sequences are only interpreted in double quoted values.
+func unquoteDotEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '\'':
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quoted value")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		for end := 1; end < len(raw); end++ {
+			switch raw[end] {
+			case '\\':
+				end++
+			case '"':
+				return strconv.Unquote(raw[:end+1])
+			}
+		}
+		return "", errors.New("unterminated double quoted value")
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		return raw, nil
+	}
+}
+
+// ValidationError reports all invalid configurations.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// FieldError describes an invalid configuration.
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) add(key, msg string) {
+	e.Errors = append(e.Errors, FieldError{key, msg})
+}
+
+func (e *ValidationError) Error() string {
+	sort.SliceStable(e.Errors, func(i, j int) bool {
+		return e.Errors[i].Key < e.Errors[j].Key
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d error(s)):", len(e.Errors))
+	for _, fe := range e.Errors {
+		fmt.Fprintf(&b, "\n  - %s: %s", fe.Key, fe.Message)
+	}
+	return b.String()
+}
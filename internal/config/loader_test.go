@@ -0,0 +1,221 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a file into a directory removed once the test is done.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed writing %s: %v", name, err)
+	}
+
+	return path
+}
+
+// setenv sets an environment variable until the test is done.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("failed setting %s: %v", key, err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+http_server_port: 8001
+prometheus_server_port: 8002
+grpc:
+  server:
+    port: 8003
+redis_host: file
+`)
+	dotEnv := writeFile(t, ".env", `
+# Comment
+PROMETHEUS_SERVER_PORT=9002
+export GRPC_SERVER_PORT="9003" # comment
+REDIS_HOST='dotenv'
+`)
+	setenv(t, "GRPC_SERVER_PORT", "10003")
+	setenv(t, "REDIS_HOST", "env")
+
+	l := &Loader{
+		File:       file,
+		DotEnvFile: dotEnv,
+		Flags:      map[string]string{"REDIS_HOST": "flag"},
+		Secrets:    defaultSecretProviders(),
+	}
+
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatalf("failed loading config: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    string
+		want   string
+		source string
+	}{
+		{"LOG_LEVEL", cfg.LogLevel, "info", SourceDefault},
+		{"HTTP_SERVER_PORT", cfg.HTTPServerPort, "8001", SourceFile},
+		{"PROMETHEUS_SERVER_PORT", cfg.PrometheusServerPort, "9002", SourceDotEnv},
+		{"GRPC_SERVER_PORT", cfg.GRPCServerPort, "10003", SourceEnv},
+		{"REDIS_HOST", cfg.RedisHost, "flag", SourceFlag},
+	}
+
+	effective := cfg.Effective()
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.key, tt.want, tt.got)
+		}
+		if source := effective[tt.key].Source; source != tt.source {
+			t.Errorf("%s: expected source %s, got %s", tt.key, tt.source, source)
+		}
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	file := writeFile(t, "config.toml", `
+graceful_timeout = "7s"
+
+[http.server]
+port = "8001"
+allow_origins = ["https://a.example.com", "https://b.example.com"]
+`)
+
+	cfg, err := (&Loader{File: file, Secrets: defaultSecretProviders()}).Load()
+	if err != nil {
+		t.Fatalf("failed loading config: %v", err)
+	}
+
+	if cfg.HTTPServerPort != "8001" {
+		t.Errorf("expected port 8001, got %q", cfg.HTTPServerPort)
+	}
+	if cfg.GracefulTimeout != 7*time.Second {
+		t.Errorf("expected graceful timeout 7s, got %v", cfg.GracefulTimeout)
+	}
+	if len(cfg.HTTPServerAllowOrigins) != 2 || cfg.HTTPServerAllowOrigins[1] != "https://b.example.com" {
+		t.Errorf("unexpected allowed origins: %q", cfg.HTTPServerAllowOrigins)
+	}
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	secret := writeFile(t, "redis_password", "s3cret\n")
+	setenv(t, "REDIS_PASSWORD_FILE", secret)
+
+	cfg, err := (&Loader{Secrets: defaultSecretProviders()}).Load()
+	if err != nil {
+		t.Fatalf("failed loading config: %v", err)
+	}
+
+	if cfg.RedisPassword != "s3cret" {
+		t.Errorf("expected password from the secret file, got %q", cfg.RedisPassword)
+	}
+	if dumped := cfg.Dump()["REDIS_PASSWORD"]; dumped != Redacted {
+		t.Errorf("expected dumped password to be redacted, got %v", dumped)
+	}
+	if strings.Contains(cfg.String(), "s3cret") {
+		t.Error("expected formatted config not to contain the password")
+	}
+}
+
+// errorKeys returns the sorted keys of the errors of a ValidationError.
+func errorKeys(t *testing.T, err error) []string {
+	t.Helper()
+
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	keys := []string{}
+	for _, fe := range verr.Errors {
+		keys = append(keys, fe.Key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+graceful_timeout: soon
+log_level: loud
+unknown_setting: 1
+`)
+
+	_, err := (&Loader{
+		File:    file,
+		Flags:   map[string]string{"HTTP_SERVER_READ_TIMEOUT": "never"},
+		Secrets: defaultSecretProviders(),
+	}).Load()
+
+	// Values which can't be decoded are reported together, before validation.
+	want := []string{"GRACEFUL_TIMEOUT", "HTTP_SERVER_READ_TIMEOUT", "UNKNOWN_SETTING"}
+	if got := errorKeys(t, err); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected errors of %v, got %v", want, got)
+	}
+	if !strings.Contains(err.Error(), "(from file)") {
+		t.Errorf("expected errors to name their source, got %q", err.Error())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, err := (&Loader{
+		Flags: map[string]string{
+			"LOG_LEVEL":                   "loud",
+			"HTTP_SERVER_ALLOW_ORIGINS":   "example.com",
+			"HTTP_SERVER_TLS_ENABLED":     "true",
+			"HTTP_SERVER_TLS_MIN_VERSION": "0.9",
+			"GRACEFUL_TIMEOUT":            "0s",
+			"TENANT_ENABLED":              "true",
+			"TENANT_RESOLVERS":            "header,jwt",
+		},
+		Secrets: defaultSecretProviders(),
+	}).Load()
+
+	want := []string{
+		"GRACEFUL_TIMEOUT",
+		"HTTP_SERVER_ALLOW_ORIGINS",
+		"HTTP_SERVER_TLS_CERT_FILE",
+		"HTTP_SERVER_TLS_KEY_FILE",
+		"HTTP_SERVER_TLS_MIN_VERSION",
+		"LOG_LEVEL",
+		"TENANT_JWT_SECRET",
+		"TENANT_RESOLVERS",
+	}
+	if got := errorKeys(t, err); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected errors of %v, got %v", want, got)
+	}
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	if _, err := (&Loader{Secrets: defaultSecretProviders()}).Load(); err != nil {
+		t.Fatalf("expected defaults to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/util"
+)
+
+// Subscriber is notified with the previous & the new configurations after a reload.
+type Subscriber func(old, new *Config)
+
+var subscribers []Subscriber
+
+// Subscribe registers a function notified after each successful reload.
+func Subscribe(s Subscriber) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers = append(subscribers, s)
+}
+
+// Reload reads the configuration sources again. Only the configurations tagged as
+// reloadable are changed, changes to other ones are reverted with a warning as they
+// require a restart. The result is then validated, invalid configurations are rejected &
+// the current ones are kept.
+func Reload() error {
+	mu.RLock()
+	l, old := loader, singleton
+	mu.RUnlock()
+
+	if l == nil || old == nil {
+		return fmt.Errorf("configurations aren't loaded yet")
+	}
+
+	cfg, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	ignored := []string{}
+	t := reflect.TypeOf(Config{})
+	oldValue, newValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(cfg).Elem()
+	for _, f := range fields() {
+		if t.Field(f.index).Tag.Get("reload") == "true" {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(f.index).Interface(), newValue.Field(f.index).Interface()) {
+			newValue.Field(f.index).Set(oldValue.Field(f.index))
+			cfg.sources[f.key] = old.sources[f.key]
+			ignored = append(ignored, f.key)
+		}
+	}
+
+	if len(ignored) > 0 {
+		log.Warn(fmt.Sprintf(
+			"Ignored changes of non-reloadable configurations, restart to apply them: %s",
+			strings.Join(ignored, ", "),
+		))
+	}
+
+	// Validated once reverted, so ignored changes can't make the reload fail.
+	if err := cfg.check(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	singleton = cfg
+	subs := append([]Subscriber{}, subscribers...)
+	mu.Unlock()
+
+	for _, s := range subs {
+		s(old, cfg)
+	}
+
+	log.Info("Configurations reloaded")
+
+	return nil
+}
+
+// Watch reloads configurations when the process receives SIGHUP, or when the config file
+// or the dotenv file is modified, checked at the given interval. A zero interval disables
+// file checks. It blocks until the context is done.
+func Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	mu.RLock()
+	l := loader
+	mu.RUnlock()
+
+	var tick <-chan time.Time
+	if interval > 0 && l != nil && (l.File != "" || l.DotEnvFile != "") {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	stamp := func() string {
+		return util.FileStamp(l.File, l.DotEnvFile)
+	}
+
+	var last string
+	if tick != nil {
+		last = stamp()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if tick != nil {
+				last = stamp()
+			}
+			log.Info("Received SIGHUP, reloading configurations")
+		case <-tick:
+			current := stamp()
+			if current == last {
+				continue
+			}
+			last = current
+			log.Info("Configuration files changed, reloading configurations")
+		}
+
+		if err := Reload(); err != nil {
+			log.Error(err, "Failed reloading configurations, keeping the current ones")
+		}
+	}
+}
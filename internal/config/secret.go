@@ -0,0 +1,296 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvSecretsFile is the environment variable of the encrypted secrets file path.
+	EnvSecretsFile = "SECRETS_FILE"
+	// EnvSecretsKey is the environment variable of the base64 encoded key of the encrypted
+	// secrets file.
+	EnvSecretsKey = "SECRETS_KEY"
+	// EnvSecretsKeyFile is the environment variable of the path of the file storing the
+	// secrets key, used instead of EnvSecretsKey.
+	EnvSecretsKeyFile = "SECRETS_KEY_FILE"
+
+	// Redacted replaces the values of secret configurations when they're dumped.
+	Redacted = "[REDACTED]"
+
+	// secretFileSuffix is appended to the keys of secret configurations to read their
+	// values from a file instead, e.g. MYSQL_PASSWORD_FILE=/run/secrets/mysql_password.
+	secretFileSuffix = "_FILE"
+)
+
+// SecretProvider resolves secret references. The value of a secret configuration is
+// a reference when it starts with the name of a registered provider followed by "://",
+// e.g. file:///run/secrets/mysql_password, env://MYSQL_ROOT_PASSWORD or
+// encrypted://mysql_password.
+type SecretProvider interface {
+	Secret(ref string) (string, error)
+}
+
+// SecretProviderFunc is an adapter to use ordinary functions as secret providers.
+type SecretProviderFunc func(ref string) (string, error)
+
+// Secret calls f(ref).
+func (f SecretProviderFunc) Secret(ref string) (string, error) {
+	return f(ref)
+}
+
+// FileSecretProvider reads secrets from files, like Docker & Kubernetes secret mounts.
+// Trailing line breaks are trimmed.
+var FileSecretProvider = SecretProviderFunc(func(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+})
+
+// EnvSecretProvider reads secrets from other environment variables.
+var EnvSecretProvider = SecretProviderFunc(func(name string) (string, error) {
+	secret, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s isn't set", name)
+	}
+	return secret, nil
+})
+
+// EncryptedFileSecretProvider reads secrets by name from a local YAML file of names &
+// secrets encrypted with AES-256-GCM, see EncryptSecrets.
+type EncryptedFileSecretProvider struct {
+	Path string
+	// Key returns the 32 bytes encryption key.
+	Key func() ([]byte, error)
+}
+
+// Secret decrypts the secrets file & returns the secret with the given name.
+func (p *EncryptedFileSecretProvider) Secret(name string) (string, error) {
+	key, err := p.Key()
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := ioutil.ReadFile(p.Path)
+	if err != nil {
+		return "", err
+	}
+
+	secrets, err := DecryptSecrets(key, encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed decrypting %s: %v", p.Path, err)
+	}
+
+	secret, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s isn't in %s", name, p.Path)
+	}
+
+	return secret, nil
+}
+
+// SecretsKeyFromEnv reads the base64 encoded key of the encrypted secrets file from the
+// environment variable, or from the file set in the environment.
+func SecretsKeyFromEnv() ([]byte, error) {
+	encoded := os.Getenv(EnvSecretsKey)
+	if path := os.Getenv(EnvSecretsKeyFile); path != "" {
+		secret, err := FileSecretProvider(path)
+		if err != nil {
+			return nil, err
+		}
+		encoded = secret
+	}
+
+	if encoded == "" {
+		return nil, fmt.Errorf("%s or %s is required", EnvSecretsKey, EnvSecretsKeyFile)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("secrets key must be 32 bytes encoded in base64")
+	}
+
+	return key, nil
+}
+
+// GenerateSecretsKey creates a random key for EncryptSecrets, encoded in base64.
+func GenerateSecretsKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecrets encrypts a YAML file of names & secrets with the given key. The result is
+// encoded in base64 so it can be committed next to other configuration files.
+func EncryptSecrets(key, plain []byte) ([]byte, error) {
+	secrets := map[string]string{}
+	if err := yaml.UnmarshalStrict(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("secrets must be a YAML map of names & secrets: %v", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(encoded, sealed)
+
+	return encoded, nil
+}
+
+// DecryptSecrets decrypts secrets encrypted by EncryptSecrets.
+func DecryptSecrets(key, encrypted []byte) (map[string]string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encrypted)))
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted secrets are truncated")
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[string]string{}
+	if err := yaml.Unmarshal(plain, &secrets); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// defaultSecretProviders returns the file & env providers, along with the encrypted file
+// provider when the encrypted secrets file is set in the environment.
+func defaultSecretProviders() map[string]SecretProvider {
+	providers := map[string]SecretProvider{
+		"file": FileSecretProvider,
+		"env":  EnvSecretProvider,
+	}
+
+	if path := os.Getenv(EnvSecretsFile); path != "" {
+		providers["encrypted"] = &EncryptedFileSecretProvider{Path: path, Key: SecretsKeyFromEnv}
+	}
+
+	return providers
+}
+
+// resolveSecret returns the secret referenced by the raw value of a secret configuration,
+// or the raw value itself when it isn't a reference.
+func (l *Loader) resolveSecret(raw string) (string, error) {
+	i := strings.Index(raw, "://")
+	if i < 1 {
+		return raw, nil
+	}
+
+	provider, ok := l.Secrets[raw[:i]]
+	if !ok {
+		return raw, nil
+	}
+
+	return provider.Secret(raw[i+3:])
+}
+
+// Dump returns the configurations by key, with the values of secret configurations
+// replaced by Redacted.
+func (c *Config) Dump() map[string]interface{} {
+	dump := map[string]interface{}{}
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range fields() {
+		value := v.Field(f.index).Interface()
+		if f.secret && value != "" {
+			value = Redacted
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		dump[f.key] = value
+	}
+	return dump
+}
+
+// Setting is the effective value of a configuration along with its source.
+type Setting struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// Effective returns the dumped configurations by key along with the source of their
+// values, i.e. one of SourceDefault, SourceFile, SourceDotEnv, SourceEnv or SourceFlag.
+func (c *Config) Effective() map[string]Setting {
+	effective := map[string]Setting{}
+	for key, value := range c.Dump() {
+		source, ok := c.sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+		effective[key] = Setting{Value: value, Source: source}
+	}
+	return effective
+}
+
+// String formats the configurations without their secrets.
+func (c Config) String() string {
+	dump := c.Dump()
+
+	keys := make([]string, 0, len(dump))
+	for key := range dump {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "%s=%v", key, dump[key])
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes the configurations without their secrets, so they can't leak into
+// logs or dumps.
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Dump())
+}
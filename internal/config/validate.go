@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/routelimit"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/tlsutil"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/stoptimeout"
+)
+
+// check validates the configurations, reporting all invalid values at once in a
+// ValidationError.
+func (c *Config) check() error {
+	errs := &ValidationError{}
+	c.validate(errs)
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate adds an error for each invalid configuration or conflicting configurations.
+func (c *Config) validate(errs *ValidationError) {
+	if err := log.ValidateLevel(c.LogLevel); err != nil {
+		errs.add("LOG_LEVEL", err.Error())
+	}
+
+	for _, origin := range c.HTTPServerAllowOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs.add("HTTP_SERVER_ALLOW_ORIGINS", fmt.Sprintf(
+				"invalid origin %q, must be * or start with http:// or https://", origin,
+			))
+		}
+	}
+
+	servers := []serverAddress{
+		{"HTTP_SERVER", c.HTTPServerPort, c.HTTPServerListen},
+		{"PROMETHEUS_SERVER", c.PrometheusServerPort, c.PrometheusServerListen},
+	}
+	if c.GRPCServerEnabled {
+		servers = append(servers, serverAddress{"GRPC_SERVER", c.GRPCServerPort, c.GRPCServerListen})
+	}
+	if c.AdminServerEnabled {
+		servers = append(servers, serverAddress{"ADMIN_SERVER", c.AdminServerPort, c.AdminServerListen})
+	}
+	validateServerAddresses(errs, servers)
+	validatePort(errs, "REDIS_PORT", c.RedisPort)
+
+	if _, err := listener.ParseNetworks(c.ProxyProtocolTrustedNetworks); err != nil {
+		errs.add("PROXY_PROTOCOL_TRUSTED_NETWORKS", err.Error())
+	}
+	if c.ProxyProtocolHeaderTimeout <= 0 {
+		errs.add("PROXY_PROTOCOL_HEADER_TIMEOUT", "must be positive")
+	}
+
+	for key, timeout := range map[string]time.Duration{
+		"HTTP_SERVER_READ_HEADER_TIMEOUT": c.HTTPServerReadHeaderTimeout,
+		"HTTP_SERVER_READ_TIMEOUT":        c.HTTPServerReadTimeout,
+		"HTTP_SERVER_WRITE_TIMEOUT":       c.HTTPServerWriteTimeout,
+		"HTTP_SERVER_IDLE_TIMEOUT":        c.HTTPServerIdleTimeout,
+	} {
+		if timeout < 0 {
+			errs.add(key, "must not be negative")
+		}
+	}
+	if c.HTTPServerMaxHeaderBytes < 1 {
+		errs.add("HTTP_SERVER_MAX_HEADER_BYTES", "must be at least 1")
+	}
+	if c.HTTPServerMaxBodyBytes == 0 || c.HTTPServerMaxBodyBytes < -1 {
+		errs.add("HTTP_SERVER_MAX_BODY_BYTES", "must be positive, or -1 for no limit")
+	}
+	if _, err := routelimit.Parse(c.HTTPServerRouteMaxBodyBytes); err != nil {
+		errs.add("HTTP_SERVER_ROUTE_MAX_BODY_BYTES", err.Error())
+	}
+
+	if c.HTTPServerHTTP3Enabled {
+		if !c.HTTPServerTLSEnabled {
+			errs.add("HTTP_SERVER_HTTP3_ENABLED", "requires HTTP_SERVER_TLS_ENABLED")
+		}
+		if c.HTTPServerHTTP3Port != "" {
+			validatePort(errs, "HTTP_SERVER_HTTP3_PORT", c.HTTPServerHTTP3Port)
+		}
+	}
+
+	if c.HTTPServerTLSEnabled {
+		if c.HTTPServerTLSCertFile == "" {
+			errs.add("HTTP_SERVER_TLS_CERT_FILE", "required when TLS is enabled")
+		}
+		if c.HTTPServerTLSKeyFile == "" {
+			errs.add("HTTP_SERVER_TLS_KEY_FILE", "required when TLS is enabled")
+		}
+		if _, err := tlsutil.ParseVersion(c.HTTPServerTLSMinVersion); err != nil {
+			errs.add("HTTP_SERVER_TLS_MIN_VERSION", err.Error())
+		}
+		if _, err := tlsutil.ParseCipherSuites(c.HTTPServerTLSCipherSuites); err != nil {
+			errs.add("HTTP_SERVER_TLS_CIPHER_SUITES", err.Error())
+		}
+		if _, err := tlsutil.ParseClientAuth(c.HTTPServerTLSClientAuth); err != nil {
+			errs.add("HTTP_SERVER_TLS_CLIENT_AUTH", err.Error())
+		}
+	}
+
+	switch strings.ToLower(c.DBDriver) {
+	case "mysql":
+		validatePort(errs, "MYSQL_PORT", c.MySQLPort)
+	case "postgres":
+		validatePort(errs, "POSTGRES_PORT", c.PostgresPort)
+	case "mssql":
+		validatePort(errs, "MSSQL_PORT", c.MSSQLPort)
+	case "sqlite":
+		if len(c.DBReplicas) > 0 {
+			errs.add("DB_REPLICAS", "sqlite databases don't support replicas")
+		}
+	default:
+		errs.add("DB_DRIVER", fmt.Sprintf(
+			"unsupported driver %q, must be one of mysql, postgres, mssql or sqlite", c.DBDriver,
+		))
+	}
+
+	for _, address := range c.DBReplicas {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || host == "" {
+			errs.add("DB_REPLICAS", fmt.Sprintf("invalid address %q, must be host:port", address))
+			continue
+		}
+		validatePort(errs, "DB_REPLICAS", port)
+	}
+	validateOneOf(errs, "DB_REPLICA_POLICY", c.DBReplicaPolicy, "round-robin", "least-connections")
+	if c.DBLogSampleRate < 0 || c.DBLogSampleRate > 1 {
+		errs.add("DB_LOG_SAMPLE_RATE", fmt.Sprintf("%v isn't between 0 and 1", c.DBLogSampleRate))
+	}
+
+	if c.MigrationsRunOnStart && c.DBAutoMigrate {
+		errs.add("DB_AUTO_MIGRATE", "conflicts with MIGRATIONS_RUN_ON_START, only one of them may manage the schema")
+	}
+
+	if c.HTTPServerRateLimitEnabled {
+		validateOneOf(errs, "HTTP_SERVER_RATE_LIMIT_ALGORITHM", c.HTTPServerRateLimitAlgorithm,
+			"sliding-window", "token-bucket")
+		validateOneOf(errs, "HTTP_SERVER_RATE_LIMIT_KEY_BY", c.HTTPServerRateLimitKeyBy,
+			"ip", "apikey", "route")
+		if c.HTTPServerRateLimitRequests < 1 {
+			errs.add("HTTP_SERVER_RATE_LIMIT_REQUESTS", "must be at least 1 when rate limit is enabled")
+		}
+		if c.HTTPServerRateLimitPeriod <= 0 {
+			errs.add("HTTP_SERVER_RATE_LIMIT_PERIOD", "must be positive when rate limit is enabled")
+		}
+	}
+
+	if c.TenantEnabled {
+		if len(c.TenantResolvers) == 0 {
+			errs.add("TENANT_RESOLVERS", "at least one resolver is required when tenancy is enabled")
+		}
+		header := false
+		for _, resolver := range c.TenantResolvers {
+			switch strings.ToLower(resolver) {
+			case "header":
+				header = true
+			case "jwt":
+				if c.TenantJWTSecret == "" {
+					errs.add("TENANT_JWT_SECRET", "required by the jwt tenant resolver")
+				}
+				if header {
+					errs.add("TENANT_RESOLVERS", "the header resolver must come after the jwt one")
+				}
+			case "subdomain":
+				if c.TenantBaseDomain == "" {
+					errs.add("TENANT_BASE_DOMAIN", "required by the subdomain tenant resolver")
+				}
+			default:
+				errs.add("TENANT_RESOLVERS", fmt.Sprintf(
+					"unsupported resolver %q, must be one of header, jwt or subdomain", resolver,
+				))
+			}
+		}
+	}
+
+	if c.HealthCheckTimeout <= 0 {
+		errs.add("HEALTH_CHECK_TIMEOUT", "must be positive")
+	}
+	if c.GracefulTimeout <= 0 {
+		errs.add("GRACEFUL_TIMEOUT", "must be positive")
+	}
+	if _, err := stoptimeout.Parse(c.ShutdownStopTimeouts); err != nil {
+		errs.add("SHUTDOWN_STOP_TIMEOUTS", err.Error())
+	}
+	if c.ShutdownPreStopDelay < 0 {
+		errs.add("SHUTDOWN_PRE_STOP_DELAY", "must not be negative")
+	}
+	if c.UpgradeTimeout <= 0 {
+		errs.add("UPGRADE_TIMEOUT", "must be positive")
+	}
+	if c.ConnectRetryInitialInterval > c.ConnectRetryMaxInterval {
+		errs.add("CONNECT_RETRY_INITIAL_INTERVAL", "exceeds CONNECT_RETRY_MAX_INTERVAL")
+	}
+}
+
+// serverAddress is where a server listens, configured by <prefix>_PORT & <prefix>_LISTEN.
+type serverAddress struct {
+	prefix string
+	port   string
+	listen string
+}
+
+// validateServerAddresses validates the ports & listener specs of the servers, and that no
+// two servers listen on the same port or spec.
+func validateServerAddresses(errs *ValidationError, servers []serverAddress) {
+	for i, s := range servers {
+		portKey, listenKey := s.prefix+"_PORT", s.prefix+"_LISTEN"
+
+		validatePort(errs, portKey, s.port)
+		if s.listen != "" {
+			if _, err := listener.ParseSpec(s.listen); err != nil {
+				errs.add(listenKey, err.Error())
+			}
+		}
+
+		for _, other := range servers[:i] {
+			switch {
+			case s.listen == "" && other.listen == "" && s.port == other.port:
+				errs.add(portKey, fmt.Sprintf(
+					"conflicts with %s_PORT, both servers listen on port %s", other.prefix, s.port,
+				))
+			case s.listen != "" && s.listen == other.listen:
+				errs.add(listenKey, fmt.Sprintf(
+					"conflicts with %s_LISTEN, both servers listen on %s", other.prefix, s.listen,
+				))
+			}
+		}
+	}
+}
+
+func validatePort(errs *ValidationError, key, port string) {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		errs.add(key, fmt.Sprintf("invalid port %q, must be between 1 and 65535", port))
+	}
+}
+
+func validateOneOf(errs *ValidationError, key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return
+		}
+	}
+	errs.add(key, fmt.Sprintf("unsupported value %q, must be one of %s", value, strings.Join(allowed, ", ")))
+}
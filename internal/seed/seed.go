@@ -0,0 +1,189 @@
+// Package seed loads fixtures of domain entities & stores them through the domain services.
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/satriajidam/go-gin-skeleton/internal/service/domain"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultSet is the fixture set loaded for every environment, before the set of the
+// environment itself.
+const DefaultSet = "default"
+
+// ProviderFixture describes a provider to seed. Providers are matched by short name.
+type ProviderFixture struct {
+	Tenant    string `json:"tenant" yaml:"tenant"`
+	ShortName string `json:"shortName" yaml:"shortName"`
+	LongName  string `json:"longName" yaml:"longName"`
+}
+
+// Fixtures stores the entities to seed.
+type Fixtures struct {
+	Providers []ProviderFixture `json:"providers" yaml:"providers"`
+}
+
+// merge adds the entities of other fixtures, replacing the ones with the same key.
+func (f *Fixtures) merge(other Fixtures) {
+	for _, p := range other.Providers {
+		replaced := false
+		for i, existing := range f.Providers {
+			if existing.Tenant == p.Tenant && existing.ShortName == p.ShortName {
+				f.Providers[i] = p
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			f.Providers = append(f.Providers, p)
+		}
+	}
+}
+
+// Load reads the YAML & JSON fixture files of the default set and of the given environment
+// set, which are the subdirectories of dir with the same names. Files are read in
+// lexical order and later entities override earlier ones.
+func Load(dir, env string) (*Fixtures, error) {
+	sets := []string{DefaultSet}
+	if env != "" && env != DefaultSet {
+		sets = append(sets, env)
+	}
+
+	fixtures := &Fixtures{}
+	found := false
+
+	for _, set := range sets {
+		paths, err := fixtureFiles(filepath.Join(dir, set))
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) > 0 {
+			found = true
+		}
+
+		for _, path := range paths {
+			f, err := readFixtures(path)
+			if err != nil {
+				return nil, err
+			}
+			fixtures.merge(*f)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no fixtures found for %s environment in %s", env, dir)
+	}
+
+	return fixtures, nil
+}
+
+func fixtureFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func readFixtures(path string) (*Fixtures, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixtures
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(b, &f)
+	} else {
+		err = yaml.UnmarshalStrict(b, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %v", path, err)
+	}
+
+	for _, p := range f.Providers {
+		if p.ShortName == "" || p.LongName == "" {
+			return nil, fmt.Errorf(
+				"invalid fixture file %s: providers require a short name & a long name", path,
+			)
+		}
+	}
+
+	return &f, nil
+}
+
+// Result counts the seeded entities.
+type Result struct {
+	Providers int
+}
+
+// Seeder stores fixtures through the domain services.
+type Seeder struct {
+	ProviderService domain.ProviderService
+	ProviderCache   domain.ProviderCache
+	// WarmCache caches the seeded entities. Otherwise their stale caches are removed.
+	WarmCache bool
+}
+
+// Run upserts the entities of the fixtures. Running it again with the same fixtures
+// doesn't change anything.
+func (s *Seeder) Run(ctx context.Context, f *Fixtures) (Result, error) {
+	var result Result
+
+	tenants := map[string]bool{}
+
+	for _, pf := range f.Providers {
+		tctx := domain.WithTenant(ctx, pf.Tenant)
+
+		p, err := s.ProviderService.UpsertProvider(tctx, pf.ShortName, pf.LongName)
+		if err != nil {
+			return result, fmt.Errorf("failed seeding provider %s: %v", pf.ShortName, err)
+		}
+
+		// The services update caches in the background, which may not happen before the
+		// seeding process exits, so they're updated here as well.
+		if s.WarmCache {
+			err = s.ProviderCache.SetCache(tctx, *p)
+		} else {
+			err = s.ProviderCache.DeleteCache(tctx, *p)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed seeding provider %s cache: %v", pf.ShortName, err)
+		}
+
+		tenants[pf.Tenant] = true
+		result.Providers++
+	}
+
+	for tenant := range tenants {
+		if err := s.ProviderCache.DeleteAllPagedCache(domain.WithTenant(ctx, tenant)); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
@@ -146,6 +146,15 @@ func FailedEntityConflict(entityName, fieldName, fieldValue string) HTTPResponse
 	}
 }
 
+func FailedEntityLocked(entityName, fieldName, fieldValue string) HTTPResponse {
+	return HTTPResponse{
+		Status:  statusFailed,
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("Concurrent modification of %s with %s: %s", entityName, fieldName, fieldValue),
+		Data:    nil,
+	}
+}
+
 func FailedGetEntity(entityName string) HTTPResponse {
 	return HTTPResponse{
 		Status: statusFailed,
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+
+	pb "github.com/satriajidam/go-gin-skeleton/api/proto/v1"
+	"github.com/satriajidam/go-gin-skeleton/internal/service/domain"
+)
+
+// PokemonGRPCHandler implements the pokemon gRPC service.
+type PokemonGRPCHandler struct {
+	pb.UnimplementedPokemonServiceServer
+	service domain.PokemonService
+}
+
+// NewPokemonGRPCHandler creates new pokemon gRPC handler.
+func NewPokemonGRPCHandler(service domain.PokemonService) *PokemonGRPCHandler {
+	return &PokemonGRPCHandler{service: service}
+}
+
+// GetPokemon gets a pokemon based on its name.
+func (h *PokemonGRPCHandler) GetPokemon(ctx context.Context, req *pb.GetPokemonRequest) (*pb.Pokemon, error) {
+	if req.GetName() == "" {
+		return nil, StatusMissingField("name")
+	}
+
+	p, err := h.service.GetPokemonByName(ctx, req.GetName())
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, StatusEntityNotFound(pokemonEntity, "name", req.GetName())
+		}
+		return nil, StatusFailedGetEntity(pokemonEntity, err)
+	}
+
+	return &pb.Pokemon{
+		Name:      p.Name,
+		Height:    int32(p.Height),
+		Weight:    int32(p.Weight),
+		Abilities: p.Abilities,
+	}, nil
+}
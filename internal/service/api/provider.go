@@ -42,6 +42,10 @@ func (h *ProviderHTTPHandler) CreateProvider(ctx *gin.Context) {
 			ResponseFailed(ctx, FailedEntityConflict(providerEntity, "shortName", req.ShortName), err)
 			return
 		}
+		if err == domain.ErrLocked {
+			ResponseFailed(ctx, FailedEntityLocked(providerEntity, "shortName", req.ShortName), err)
+			return
+		}
 		ResponseFailed(ctx, FailedCreateEntity(providerEntity), err)
 		return
 	}
@@ -80,6 +84,10 @@ func (h *ProviderHTTPHandler) UpdateProvider(ctx *gin.Context) {
 			ResponseFailed(ctx, FailedEntityConflict(providerEntity, "shortName", req.ShortName), err)
 			return
 		}
+		if err == domain.ErrLocked {
+			ResponseFailed(ctx, FailedEntityLocked(providerEntity, "shortName", req.ShortName), err)
+			return
+		}
 		if err == domain.ErrNotFound {
 			ResponseFailed(ctx, FailedEntityNotFound(providerEntity, "uuid", uuid), err)
 			return
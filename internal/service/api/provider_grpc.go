@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+
+	pb "github.com/satriajidam/go-gin-skeleton/api/proto/v1"
+	"github.com/satriajidam/go-gin-skeleton/internal/service/domain"
+)
+
+// ProviderGRPCHandler implements the provider gRPC service.
+type ProviderGRPCHandler struct {
+	pb.UnimplementedProviderServiceServer
+	service domain.ProviderService
+}
+
+// NewProviderGRPCHandler creates new provider gRPC handler.
+func NewProviderGRPCHandler(service domain.ProviderService) *ProviderGRPCHandler {
+	return &ProviderGRPCHandler{service: service}
+}
+
+func providerToProto(p *domain.Provider) *pb.Provider {
+	return &pb.Provider{
+		Uuid:      p.UUID,
+		ShortName: p.ShortName,
+		LongName:  p.LongName,
+	}
+}
+
+// CreateProvider creates new provider.
+func (h *ProviderGRPCHandler) CreateProvider(
+	ctx context.Context, req *pb.CreateProviderRequest,
+) (*pb.Provider, error) {
+	if req.GetShortName() == "" {
+		return nil, StatusMissingField("short_name")
+	}
+	if req.GetLongName() == "" {
+		return nil, StatusMissingField("long_name")
+	}
+
+	p, err := h.service.CreateProvider(ctx, req.GetShortName(), req.GetLongName())
+	if err != nil {
+		if err == domain.ErrConflict {
+			return nil, StatusEntityConflict(providerEntity, "short_name", req.GetShortName())
+		}
+		if err == domain.ErrLocked {
+			return nil, StatusEntityLocked(providerEntity, "short_name", req.GetShortName())
+		}
+		return nil, StatusFailedCreateEntity(providerEntity, err)
+	}
+
+	return providerToProto(p), nil
+}
+
+// UpdateProvider updates existing provider.
+func (h *ProviderGRPCHandler) UpdateProvider(
+	ctx context.Context, req *pb.UpdateProviderRequest,
+) (*pb.Provider, error) {
+	if req.GetUuid() == "" {
+		return nil, StatusMissingField("uuid")
+	}
+	if req.GetShortName() == "" && req.GetLongName() == "" {
+		return nil, StatusEmptyPayload()
+	}
+
+	p, err := h.service.UpdateProvider(ctx, req.GetUuid(), req.GetShortName(), req.GetLongName())
+	if err != nil {
+		if err == domain.ErrConflict {
+			return nil, StatusEntityConflict(providerEntity, "short_name", req.GetShortName())
+		}
+		if err == domain.ErrLocked {
+			return nil, StatusEntityLocked(providerEntity, "short_name", req.GetShortName())
+		}
+		if err == domain.ErrNotFound {
+			return nil, StatusEntityNotFound(providerEntity, "uuid", req.GetUuid())
+		}
+		return nil, StatusFailedUpdateEntity(providerEntity, err)
+	}
+
+	return providerToProto(p), nil
+}
+
+// GetProvider retrieves a provider based on its UUID.
+func (h *ProviderGRPCHandler) GetProvider(
+	ctx context.Context, req *pb.GetProviderRequest,
+) (*pb.Provider, error) {
+	if req.GetUuid() == "" {
+		return nil, StatusMissingField("uuid")
+	}
+
+	p, err := h.service.GetProviderByUUID(ctx, req.GetUuid())
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, StatusEntityNotFound(providerEntity, "uuid", req.GetUuid())
+		}
+		return nil, StatusFailedGetEntity(providerEntity, err)
+	}
+
+	return providerToProto(p), nil
+}
+
+// ListProviders gets a page of providers.
+func (h *ProviderGRPCHandler) ListProviders(
+	ctx context.Context, req *pb.ListProvidersRequest,
+) (*pb.ListProvidersResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = 10
+	}
+
+	ps, err := h.service.GetProviders(ctx, int(req.GetOffset()), limit)
+	if err != nil {
+		return nil, StatusFailedGetEntity(providerEntities, err)
+	}
+
+	resp := &pb.ListProvidersResponse{Providers: make([]*pb.Provider, 0, len(ps))}
+	for i := range ps {
+		resp.Providers = append(resp.Providers, providerToProto(&ps[i]))
+	}
+
+	return resp, nil
+}
+
+// DeleteProvider deletes existing provider based on its UUID.
+func (h *ProviderGRPCHandler) DeleteProvider(
+	ctx context.Context, req *pb.DeleteProviderRequest,
+) (*pb.DeleteProviderResponse, error) {
+	if req.GetUuid() == "" {
+		return nil, StatusMissingField("uuid")
+	}
+
+	if err := h.service.DeleteProviderByUUID(ctx, req.GetUuid()); err != nil {
+		if err == domain.ErrNotFound {
+			return nil, StatusEntityNotFound(providerEntity, "uuid", req.GetUuid())
+		}
+		return nil, StatusFailedDeleteEntity(providerEntity, err)
+	}
+
+	return &pb.DeleteProviderResponse{}, nil
+}
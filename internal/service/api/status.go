@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failedStatus is a gRPC error which hides its cause from clients, while the cause is
+// still reported to the logger interceptor, like ResponseFailed does for HTTP.
+type failedStatus struct {
+	status *status.Status
+	cause  error
+}
+
+func (e *failedStatus) Error() string {
+	return e.cause.Error()
+}
+
+// GRPCStatus returns the status sent to clients.
+func (e *failedStatus) GRPCStatus() *status.Status {
+	return e.status
+}
+
+func newFailedStatus(c codes.Code, msg string, err error) error {
+	if err == nil {
+		return status.Error(c, msg)
+	}
+	return &failedStatus{status: status.New(c, msg), cause: err}
+}
+
+func StatusEmptyPayload() error {
+	return status.Error(codes.InvalidArgument, "Empty payload")
+}
+
+func StatusMissingField(field string) error {
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("Missing '%s' field", field))
+}
+
+func StatusEntityNotFound(entityName, fieldName, fieldValue string) error {
+	return status.Error(
+		codes.NotFound,
+		fmt.Sprintf("No %s was found with %s: %s", entityName, fieldName, fieldValue),
+	)
+}
+
+func StatusEntityConflict(entityName, fieldName, fieldValue string) error {
+	return status.Error(
+		codes.AlreadyExists,
+		fmt.Sprintf("Duplicate entry for %s with %s: %s", entityName, fieldName, fieldValue),
+	)
+}
+
+func StatusEntityLocked(entityName, fieldName, fieldValue string) error {
+	return status.Error(
+		codes.Aborted,
+		fmt.Sprintf("Concurrent modification of %s with %s: %s", entityName, fieldName, fieldValue),
+	)
+}
+
+func StatusFailedGetEntity(entityName string, err error) error {
+	return newFailedStatus(codes.Internal, fmt.Sprintf("Failed %s %s", actionGet, entityName), err)
+}
+
+func StatusFailedCreateEntity(entityName string, err error) error {
+	return newFailedStatus(codes.Internal, fmt.Sprintf("Failed %s %s", actionCreate, entityName), err)
+}
+
+func StatusFailedUpdateEntity(entityName string, err error) error {
+	return newFailedStatus(codes.Internal, fmt.Sprintf("Failed %s %s", actionUpdate, entityName), err)
+}
+
+func StatusFailedDeleteEntity(entityName string, err error) error {
+	return newFailedStatus(codes.Internal, fmt.Sprintf("Failed %s %s", actionDelete, entityName), err)
+}
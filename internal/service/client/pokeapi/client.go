@@ -1,6 +1,7 @@
 package pokeapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -33,6 +34,18 @@ func NewClient(apiURL string, timeoutSeconds time.Duration) *Client {
 	}
 }
 
+// Check ensures pokeapi.co is reachable & not failing, implementing health.Checker.
+func (c *Client) Check(ctx context.Context) error {
+	resp, err := c.client.R().SetContext(ctx).SetHeader("Accept", "application/json").Get("/")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return ErrServerSide
+	}
+	return nil
+}
+
 // GetPokemonByName gets a pokemon based on its name.
 func (c *Client) GetPokemonByName(name string) (*Pokemon, error) {
 	resp, err := c.client.R().SetHeader("Accept", "application/json").
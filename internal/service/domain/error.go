@@ -7,4 +7,6 @@ var (
 	ErrNotFound = errors.New("Entity not found")
 	// ErrConflict occurs when an action tries to create entity that already exists.
 	ErrConflict = errors.New("Entity already exists")
+	// ErrLocked occurs when an action tries to modify entity that is being modified by another action.
+	ErrLocked = errors.New("Entity is locked")
 )
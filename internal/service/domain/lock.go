@@ -0,0 +1,8 @@
+package domain
+
+import "context"
+
+// Lock represents a distributed lock held on a shared entity.
+type Lock interface {
+	Release(ctx context.Context) error
+}
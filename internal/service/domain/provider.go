@@ -13,9 +13,11 @@ type Provider struct {
 type ProviderService interface {
 	CreateProvider(ctx context.Context, shortName, longName string) (*Provider, error)
 	UpdateProvider(ctx context.Context, uuid, shortName, longName string) (*Provider, error)
+	UpsertProvider(ctx context.Context, shortName, longName string) (*Provider, error)
 	GetProviderByUUID(ctx context.Context, uuid string) (*Provider, error)
 	GetProviders(ctx context.Context, offset, limit int) ([]Provider, error)
 	DeleteProviderByUUID(ctx context.Context, uuid string) error
+	FlushCache(ctx context.Context) error
 }
 
 // ProviderRepository provides methods for interacting with Provider repository.
@@ -25,6 +27,7 @@ type ProviderRepository interface {
 	DeleteProviderByUUID(ctx context.Context, uuid string) error
 	GetProviderByUUID(ctx context.Context, uuid string) (*Provider, error)
 	GetProviderByShortName(ctx context.Context, shortName string) (*Provider, error)
+	GetLatestProviderByShortName(ctx context.Context, shortName string) (*Provider, error)
 	GetProviders(ctx context.Context, offset, limit int) ([]Provider, error)
 }
 
@@ -41,4 +44,6 @@ type ProviderCache interface {
 	SetPagedCache(ctx context.Context, offset, limit int, ps []Provider) error
 	DeleteAllPagedCache(ctx context.Context) error
 	DeleteCache(ctx context.Context, p Provider) error
+	DeleteAllCache(ctx context.Context) error
+	LockByShortName(ctx context.Context, shortName string) (Lock, error)
 }
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"net/http"
+)
+
+// tenantKey is the context key of the ID of the tenant owning the accessed entities.
+type tenantKey struct{}
+
+// TenantFromContext returns the tenant ID stored in the context, which is empty for
+// single tenant deployments. Gin contexts only resolve their own string keys, so the
+// tenant is looked up in the context of the request they return for the 0 key.
+func TenantFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+		return tenant
+	}
+	if req, ok := ctx.Value(0).(*http.Request); ok && req != nil {
+		tenant, _ := req.Context().Value(tenantKey{}).(string)
+		return tenant
+	}
+	return ""
+}
+
+// WithTenant returns a copy of the context carrying the given tenant ID.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// DetachTenant returns a background context carrying the tenant ID of the given context,
+// for work which outlives the request the context belongs to.
+func DetachTenant(ctx context.Context) context.Context {
+	return WithTenant(context.Background(), TenantFromContext(ctx))
+}
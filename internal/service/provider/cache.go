@@ -12,6 +12,10 @@ import (
 var (
 	singleCacheTTL = 12 * time.Hour
 	pagedCacheTTL  = 1 * time.Hour
+	lockTTL        = 10 * time.Second
+	lockWait       = 5 * time.Second
+	// lockReleaseTimeout bounds releasing a lock, which doesn't depend on the request.
+	lockReleaseTimeout = 2 * time.Second
 )
 
 type cache struct {
@@ -24,15 +28,24 @@ func NewCache(rc *redis.Connection) domain.ProviderCache {
 	return &cache{rc, "provider"}
 }
 
-func (c *cache) prefixedKey(key string) string {
-	return fmt.Sprintf("%s:%s", c.prefix, key)
+// cacheKey builds the key of a cached value as "provider:t:<tenant>:<kind>:<id>". The
+// tenant segment is empty for single tenant deployments, & tenant IDs never contain
+// colons, so tenants never share nor clear each other's cached providers.
+func (c *cache) cacheKey(ctx context.Context, kind, id string) string {
+	return fmt.Sprintf("%s:t:%s:%s:%s", c.prefix, domain.TenantFromContext(ctx), kind, id)
+}
+
+// lockKey builds the key of a lock as "provider:lock:t:<tenant>:<shortName>", outside of
+// the cached values so clearing them never drops held locks.
+func (c *cache) lockKey(ctx context.Context, shortName string) string {
+	return fmt.Sprintf("%s:lock:t:%s:%s", c.prefix, domain.TenantFromContext(ctx), shortName)
 }
 
 // GetCacheByUUID gets a cached provider based on its UUID.
 func (c *cache) GetCacheByUUID(ctx context.Context, uuid string) (*domain.Provider, error) {
 	var p domain.Provider
 
-	if err := c.rc.GetCache(ctx, c.prefixedKey(uuid), &p); err != nil {
+	if err := c.rc.GetCache(ctx, c.cacheKey(ctx, "uuid", uuid), &p); err != nil {
 		if err == redis.ErrNoCache {
 			return nil, nil
 		}
@@ -44,19 +57,19 @@ func (c *cache) GetCacheByUUID(ctx context.Context, uuid string) (*domain.Provid
 
 // SetCacheByUUID caches a provider using its UUID as the cache key.
 func (c *cache) SetCacheByUUID(ctx context.Context, p domain.Provider) error {
-	return c.rc.SetCache(ctx, c.prefixedKey(p.UUID), p, singleCacheTTL)
+	return c.rc.SetCache(ctx, c.cacheKey(ctx, "uuid", p.UUID), p, singleCacheTTL)
 }
 
 // DeleteCacheByUUID removes a cached provider based on its UUID.
 func (c *cache) DeleteCacheByUUID(ctx context.Context, uuid string) error {
-	return c.rc.DeleteCache(ctx, c.prefixedKey(uuid))
+	return c.rc.DeleteCache(ctx, c.cacheKey(ctx, "uuid", uuid))
 }
 
 // GetCacheByShortName gets a cached provider based on its short name.
 func (c *cache) GetCacheByShortName(ctx context.Context, shortName string) (*domain.Provider, error) {
 	var uuid string
 
-	if err := c.rc.GetCache(ctx, c.prefixedKey(shortName), &uuid); err != nil {
+	if err := c.rc.GetCache(ctx, c.cacheKey(ctx, "short", shortName), &uuid); err != nil {
 		if err == redis.ErrNoCache {
 			return nil, nil
 		}
@@ -72,12 +85,12 @@ func (c *cache) GetCacheByShortName(ctx context.Context, shortName string) (*dom
 
 // SetCacheByShortName caches a provider UUID using its short name as the cache key.
 func (c *cache) SetCacheByShortName(ctx context.Context, shortName, uuid string) error {
-	return c.rc.SetCache(ctx, c.prefixedKey(shortName), uuid, singleCacheTTL)
+	return c.rc.SetCache(ctx, c.cacheKey(ctx, "short", shortName), uuid, singleCacheTTL)
 }
 
 // DeleteCacheByShortName removes a cached provider based on its short name.
 func (c *cache) DeleteCacheByShortName(ctx context.Context, shortName string) error {
-	return c.rc.DeleteCache(ctx, c.prefixedKey(shortName))
+	return c.rc.DeleteCache(ctx, c.cacheKey(ctx, "short", shortName))
 }
 
 // SetCache caches a provider.
@@ -93,8 +106,8 @@ func (c *cache) SetCache(ctx context.Context, p domain.Provider) error {
 	return nil
 }
 
-func (c *cache) pagedCacheKey(offset, limit int) string {
-	return c.prefixedKey(fmt.Sprintf("paged:%d:%d", offset, limit))
+func (c *cache) pagedCacheKey(ctx context.Context, offset, limit int) string {
+	return c.cacheKey(ctx, "paged", fmt.Sprintf("%d:%d", offset, limit))
 }
 
 func (c *cache) getPagedCache(ctx context.Context, uuids []string) ([]domain.Provider, error) {
@@ -118,7 +131,7 @@ func (c *cache) getPagedCache(ctx context.Context, uuids []string) ([]domain.Pro
 func (c *cache) GetPagedCache(ctx context.Context, offset, limit int) ([]domain.Provider, error) {
 	var uuids []string
 
-	err := c.rc.GetCache(ctx, c.pagedCacheKey(offset, limit), &uuids)
+	err := c.rc.GetCache(ctx, c.pagedCacheKey(ctx, offset, limit), &uuids)
 	if err != nil {
 		if err == redis.ErrNoCache {
 			return nil, nil
@@ -142,12 +155,12 @@ func (c *cache) SetPagedCache(ctx context.Context, offset, limit int, ps []domai
 		}
 		uuids = append(uuids, p.UUID)
 	}
-	return c.rc.SetCache(ctx, c.pagedCacheKey(offset, limit), uuids, pagedCacheTTL)
+	return c.rc.SetCache(ctx, c.pagedCacheKey(ctx, offset, limit), uuids, pagedCacheTTL)
 }
 
 // DeleteCache removes all paged caches.
 func (c *cache) DeleteAllPagedCache(ctx context.Context) error {
-	if err := c.rc.DeleteCacheByPrefix(ctx, c.prefixedKey("paged:*")); err != nil {
+	if err := c.rc.DeleteCacheByPrefix(ctx, c.cacheKey(ctx, "paged", "*")); err != nil {
 		return err
 	}
 	return nil
@@ -169,3 +182,26 @@ func (c *cache) DeleteCache(ctx context.Context, p domain.Provider) error {
 
 	return nil
 }
+
+// DeleteAllCache removes the cached providers of all tenants. Locks aren't cached values,
+// so they're kept.
+func (c *cache) DeleteAllCache(ctx context.Context) error {
+	return c.rc.DeleteCacheByPrefix(ctx, fmt.Sprintf("%s:t:*", c.prefix))
+}
+
+// LockByShortName obtains a distributed lock on a provider short name. Writes can't be
+// serialized without Redis, so it fails while Redis isn't connected.
+func (c *cache) LockByShortName(ctx context.Context, shortName string) (domain.Lock, error) {
+	if c.rc == nil {
+		return nil, redis.ErrNotConnected
+	}
+
+	lock, err := c.rc.ObtainLock(ctx, c.lockKey(ctx, shortName), lockTTL, lockWait)
+	if err != nil {
+		if err == redis.ErrLockNotObtained {
+			return nil, domain.ErrLocked
+		}
+		return nil, err
+	}
+	return lock, nil
+}
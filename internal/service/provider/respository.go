@@ -10,11 +10,13 @@ import (
 	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
 )
 
-// ProviderSQLModel is a SQL database model for provider.
+// ProviderSQLModel is a SQL database model for provider. Short names are unique per tenant
+// among live providers, which gorm tags can't declare, see migrateShortNameIndex.
 type ProviderSQLModel struct {
 	ID        uint       `gorm:"column:id;PRIMARY_KEY"`
+	TenantID  string     `gorm:"column:tenant_id;INDEX:idx_provider_tenant_short_name;NOT NULL;DEFAULT:''"`
 	UUID      string     `gorm:"column:uuid;UNIQUE;UNIQUE_INDEX;NOT NULL"`
-	ShortName string     `gorm:"column:short_name;INDEX;NOT NULL"`
+	ShortName string     `gorm:"column:short_name;INDEX:idx_provider_tenant_short_name;NOT NULL"`
 	LongName  string     `gorm:"column:long_name;NOT NULL"`
 	CreatedAt time.Time  `gorm:"column:created_at;NOT NULL"`
 	UpdatedAt time.Time  `gorm:"column:updated_at;NOT NULL"`
@@ -38,17 +40,72 @@ type repository struct {
 	conn *sql.Connection
 }
 
+// tenantScope restricts queries to the providers of the tenant found in the context.
+func tenantScope(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	tenant := domain.TenantFromContext(ctx)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("tenant_id = ?", tenant)
+	}
+}
+
+// reader returns the database for read queries, scoped to the tenant of the context.
+func (r *repository) reader(ctx context.Context) *gorm.DB {
+	return r.conn.Reader(ctx).Scopes(tenantScope(ctx))
+}
+
+// writer returns the database for write queries, scoped to the tenant of the context.
+func (r *repository) writer(ctx context.Context) *gorm.DB {
+	return r.conn.Writer(ctx).Scopes(tenantScope(ctx))
+}
+
 // NewRepository creates new provider repository.
 func NewRepository(conn *sql.Connection, automigrate bool) domain.ProviderRepository {
 	if automigrate {
 		conn.DB.AutoMigrate(&ProviderSQLModel{})
+		migrateShortNameIndex(conn)
 	}
 	return &repository{conn}
 }
 
+// migrateShortNameIndex creates the unique index of short names, leaving out soft deleted
+// providers so they can be recreated. It matches the one created by the migrations.
+func migrateShortNameIndex(conn *sql.Connection) {
+	const index = "uix_provider_tenant_short_name"
+
+	if conn.DB.Dialect().HasIndex("provider", index) {
+		return
+	}
+
+	stmts := []string{}
+	switch conn.Dialect() {
+	case sql.DialectMySQL:
+		// MySQL has no partial indexes, so a generated column holding the short names of
+		// live providers is indexed instead, since unique indexes allow any number of NULLs.
+		if !conn.DB.Dialect().HasColumn("provider", "live_short_name") {
+			stmts = append(stmts, "ALTER TABLE provider ADD COLUMN live_short_name VARCHAR(255) "+
+				"AS (IF(deleted_at IS NULL, short_name, NULL)) STORED")
+		}
+		stmts = append(stmts, fmt.Sprintf(
+			"CREATE UNIQUE INDEX %s ON provider (tenant_id, live_short_name)", index,
+		))
+	default:
+		stmts = append(stmts, fmt.Sprintf(
+			"CREATE UNIQUE INDEX %s ON provider (tenant_id, short_name) WHERE deleted_at IS NULL", index,
+		))
+	}
+
+	for _, stmt := range stmts {
+		if err := conn.DB.Exec(stmt).Error; err != nil {
+			conn.LogError(err, "Failed creating unique index of provider short names")
+			return
+		}
+	}
+}
+
 // CreateProvider creates new provider in the database.
 func (r *repository) CreateProvider(ctx context.Context, p domain.Provider) error {
-	if err := r.conn.DB.Create(&ProviderSQLModel{
+	if err := r.conn.Writer(ctx).Create(&ProviderSQLModel{
+		TenantID:  domain.TenantFromContext(ctx),
 		UUID:      p.UUID,
 		ShortName: p.ShortName,
 		LongName:  p.LongName,
@@ -56,6 +113,9 @@ func (r *repository) CreateProvider(ctx context.Context, p domain.Provider) erro
 		UpdatedAt: time.Now(),
 		DeletedAt: nil,
 	}).Error; err != nil {
+		if r.conn.IsDuplicateKey(err) {
+			return domain.ErrConflict
+		}
 		r.conn.LogError(err, "Failed creating new provider")
 		return err
 	}
@@ -64,7 +124,7 @@ func (r *repository) CreateProvider(ctx context.Context, p domain.Provider) erro
 
 // UpdateProvider updates the existing provider in the database.
 func (r *repository) UpdateProvider(ctx context.Context, p domain.Provider) error {
-	if err := r.conn.DB.Model(&ProviderSQLModel{}).
+	if err := r.writer(ctx).Model(&ProviderSQLModel{}).
 		Where("uuid = ? AND deleted_at IS NULL", p.UUID).Updates(
 		map[string]interface{}{
 			"short_name": p.ShortName,
@@ -75,6 +135,9 @@ func (r *repository) UpdateProvider(ctx context.Context, p domain.Provider) erro
 		if gorm.IsRecordNotFoundError(err) {
 			return domain.ErrNotFound
 		}
+		if r.conn.IsDuplicateKey(err) {
+			return domain.ErrConflict
+		}
 		r.conn.LogError(err, fmt.Sprintf("Failed updating provider with '%s' UUID", p.UUID))
 		return err
 	}
@@ -83,7 +146,7 @@ func (r *repository) UpdateProvider(ctx context.Context, p domain.Provider) erro
 
 // DeleteProviderByUUID deletes existing provider in the database based on its UUID.
 func (r *repository) DeleteProviderByUUID(ctx context.Context, uuid string) error {
-	if err := r.conn.DB.Where("uuid = ?", uuid).Delete(&ProviderSQLModel{}).Error; err != nil {
+	if err := r.writer(ctx).Where("uuid = ?", uuid).Delete(&ProviderSQLModel{}).Error; err != nil {
 		r.conn.LogError(err, fmt.Sprintf("Failed deleting provider with '%s' UUID", uuid))
 		return err
 	}
@@ -93,7 +156,7 @@ func (r *repository) DeleteProviderByUUID(ctx context.Context, uuid string) erro
 // GetProviderByUUID gets a provider in the database based on its UUID.
 func (r *repository) GetProviderByUUID(ctx context.Context, uuid string) (*domain.Provider, error) {
 	var pm ProviderSQLModel
-	if err := r.conn.DB.Where("uuid = ? AND deleted_at IS NULL", uuid).First(&pm).Error; err != nil {
+	if err := r.reader(ctx).Where("uuid = ? AND deleted_at IS NULL", uuid).First(&pm).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return nil, domain.ErrNotFound
 		}
@@ -105,8 +168,20 @@ func (r *repository) GetProviderByUUID(ctx context.Context, uuid string) (*domai
 
 // GetProviderByShortName gets a provider in the database based on its short name.
 func (r *repository) GetProviderByShortName(ctx context.Context, shortName string) (*domain.Provider, error) {
+	return r.getProviderByShortName(ctx, r.reader(ctx), shortName)
+}
+
+// GetLatestProviderByShortName gets a provider in the primary database based on its short
+// name, so it sees writes which aren't replicated yet, as uniqueness checks must.
+func (r *repository) GetLatestProviderByShortName(ctx context.Context, shortName string) (*domain.Provider, error) {
+	return r.getProviderByShortName(ctx, r.writer(ctx), shortName)
+}
+
+func (r *repository) getProviderByShortName(
+	ctx context.Context, db *gorm.DB, shortName string,
+) (*domain.Provider, error) {
 	var pm ProviderSQLModel
-	if err := r.conn.DB.Where("short_name = ? AND deleted_at IS NULL", shortName).First(&pm).Error; err != nil {
+	if err := db.Where("short_name = ? AND deleted_at IS NULL", shortName).First(&pm).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return nil, domain.ErrNotFound
 		}
@@ -125,7 +200,8 @@ func (r *repository) GetProviders(ctx context.Context, offset, limit int) ([]dom
 	if limit < 1 {
 		limit = 1
 	}
-	if err := r.conn.DB.Offset(offset).Limit(limit).Find(&pms).Error; err != nil {
+	// Pages need a stable order, which SQL Server also requires to page at all.
+	if err := r.reader(ctx).Order("id").Offset(offset).Limit(limit).Find(&pms).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return nil, nil
 		}
@@ -17,33 +17,29 @@ func NewService(repo domain.ProviderRepository, cache domain.ProviderCache) doma
 	return &service{repo, cache}
 }
 
-func (s *service) getProviderByShortName(ctx context.Context, shortName string) (*domain.Provider, error) {
-	var result *domain.Provider
-
-	if cached, _ := s.cache.GetCacheByShortName(ctx, shortName); cached == nil {
-		p, err := s.repo.GetProviderByShortName(ctx, shortName)
-		if err != nil {
-			return nil, err
-		}
-
-		if p != nil {
-			go func() {
-				_ = s.cache.SetCacheByShortName(ctx, p.ShortName, p.UUID)
-			}()
-			result = p
-		}
-	} else {
-		result = cached
-	}
-
-	return result, nil
+// releaseLock releases the lock even when the request context is already cancelled, so the
+// short name isn't kept locked until the lock expires.
+func releaseLock(lock domain.Lock) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockReleaseTimeout)
+	defer cancel()
+	_ = lock.Release(ctx)
 }
 
 // CreateProvider creates new provider.
 func (s *service) CreateProvider(
 	ctx context.Context, shortName, longName string,
 ) (*domain.Provider, error) {
-	conflicting, err := s.getProviderByShortName(ctx, shortName)
+	// Serialize creation of providers with the same short name across replicas.
+	// Short names are unique per tenant, and so is the lock.
+	lock, err := s.cache.LockByShortName(ctx, shortName)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock(lock)
+
+	// The cache & replicas may lag behind, so only the primary database can tell whether
+	// the short name is taken.
+	conflicting, err := s.repo.GetLatestProviderByShortName(ctx, shortName)
 	if err != nil && err != domain.ErrNotFound {
 		return nil, err
 	}
@@ -52,6 +48,13 @@ func (s *service) CreateProvider(
 		return nil, domain.ErrConflict
 	}
 
+	return s.createProvider(ctx, shortName, longName)
+}
+
+// createProvider stores a new provider. The caller must hold the short name lock.
+func (s *service) createProvider(
+	ctx context.Context, shortName, longName string,
+) (*domain.Provider, error) {
 	p := domain.Provider{
 		UUID:      ksuid.New().String(),
 		ShortName: shortName,
@@ -62,10 +65,10 @@ func (s *service) CreateProvider(
 		return nil, err
 	}
 
-	go func() {
+	go func(ctx context.Context) {
 		_ = s.cache.DeleteAllPagedCache(ctx)
 		_ = s.cache.SetCacheByUUID(ctx, p)
-	}()
+	}(domain.DetachTenant(ctx))
 
 	return &p, nil
 }
@@ -74,21 +77,27 @@ func (s *service) CreateProvider(
 func (s *service) UpdateProvider(
 	ctx context.Context, uuid, shortName, longName string,
 ) (*domain.Provider, error) {
-	conflicting, err := s.getProviderByShortName(ctx, shortName)
-	if err != nil && err != domain.ErrNotFound {
-		return nil, err
-	}
-
 	existing, err := s.GetProviderByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
-	if conflicting != nil && conflicting.UUID != existing.UUID {
-		return nil, domain.ErrConflict
-	}
+	if shortName != "" && shortName != existing.ShortName {
+		lock, err := s.cache.LockByShortName(ctx, shortName)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseLock(lock)
+
+		conflicting, err := s.repo.GetLatestProviderByShortName(ctx, shortName)
+		if err != nil && err != domain.ErrNotFound {
+			return nil, err
+		}
+
+		if conflicting != nil && conflicting.UUID != existing.UUID {
+			return nil, domain.ErrConflict
+		}
 
-	if shortName != "" {
 		existing.ShortName = shortName
 	}
 
@@ -100,9 +109,47 @@ func (s *service) UpdateProvider(
 		return nil, err
 	}
 
-	go func() {
+	go func(ctx context.Context) {
 		_ = s.cache.SetCacheByUUID(ctx, *existing)
-	}()
+	}(domain.DetachTenant(ctx))
+
+	return existing, nil
+}
+
+// UpsertProvider creates a provider, or updates the long name of the provider having the
+// same short name.
+func (s *service) UpsertProvider(
+	ctx context.Context, shortName, longName string,
+) (*domain.Provider, error) {
+	lock, err := s.cache.LockByShortName(ctx, shortName)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock(lock)
+
+	existing, err := s.repo.GetLatestProviderByShortName(ctx, shortName)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	if existing == nil {
+		return s.createProvider(ctx, shortName, longName)
+	}
+
+	if existing.LongName == longName {
+		return existing, nil
+	}
+
+	existing.LongName = longName
+
+	if err := s.repo.UpdateProvider(ctx, *existing); err != nil {
+		return nil, err
+	}
+
+	go func(ctx context.Context) {
+		_ = s.cache.DeleteAllPagedCache(ctx)
+		_ = s.cache.SetCacheByUUID(ctx, *existing)
+	}(domain.DetachTenant(ctx))
 
 	return existing, nil
 }
@@ -118,9 +165,9 @@ func (s *service) GetProviderByUUID(ctx context.Context, uuid string) (*domain.P
 		}
 
 		if p != nil {
-			go func() {
+			go func(ctx context.Context) {
 				_ = s.cache.SetCacheByUUID(ctx, *p)
-			}()
+			}(domain.DetachTenant(ctx))
 			result = p
 		}
 	} else {
@@ -149,9 +196,9 @@ func (s *service) GetProviders(ctx context.Context, offset, limit int) ([]domain
 		}
 
 		if len(ps) > 0 {
-			go func() {
+			go func(ctx context.Context) {
 				_ = s.cache.SetPagedCache(ctx, offset, limit, ps)
-			}()
+			}(domain.DetachTenant(ctx))
 			result = ps
 		}
 	} else {
@@ -172,9 +219,15 @@ func (s *service) DeleteProviderByUUID(ctx context.Context, uuid string) error {
 		return err
 	}
 
-	go func() {
+	go func(ctx context.Context) {
 		_ = s.cache.DeleteCache(ctx, *p)
-	}()
+	}(domain.DetachTenant(ctx))
 
 	return nil
 }
+
+// FlushCache removes the cached providers of all tenants, so they're read from the
+// repository again.
+func (s *service) FlushCache(ctx context.Context) error {
+	return s.cache.DeleteAllCache(ctx)
+}
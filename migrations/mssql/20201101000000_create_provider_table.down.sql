@@ -0,0 +1,2 @@
+IF OBJECT_ID(N'provider', N'U') IS NOT NULL
+DROP TABLE provider;
@@ -0,0 +1,14 @@
+IF OBJECT_ID(N'provider', N'U') IS NULL
+CREATE TABLE provider (
+  id INT IDENTITY(1,1) PRIMARY KEY,
+  uuid NVARCHAR(255) NOT NULL,
+  short_name NVARCHAR(255) NOT NULL,
+  long_name NVARCHAR(255) NOT NULL,
+  created_at DATETIMEOFFSET NOT NULL,
+  updated_at DATETIMEOFFSET NOT NULL,
+  deleted_at DATETIMEOFFSET NULL
+);
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'uix_provider_uuid')
+CREATE UNIQUE INDEX uix_provider_uuid ON provider (uuid);
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'idx_provider_short_name')
+CREATE INDEX idx_provider_short_name ON provider (short_name);
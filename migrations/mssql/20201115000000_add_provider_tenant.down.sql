@@ -0,0 +1,6 @@
+IF EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'idx_provider_tenant_short_name')
+DROP INDEX idx_provider_tenant_short_name ON provider;
+ALTER TABLE provider DROP CONSTRAINT df_provider_tenant_id;
+ALTER TABLE provider DROP COLUMN tenant_id;
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'idx_provider_short_name')
+CREATE INDEX idx_provider_short_name ON provider (short_name);
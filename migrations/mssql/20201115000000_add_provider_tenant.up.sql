@@ -0,0 +1,5 @@
+ALTER TABLE provider ADD tenant_id NVARCHAR(64) NOT NULL CONSTRAINT df_provider_tenant_id DEFAULT '';
+IF EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'idx_provider_short_name')
+DROP INDEX idx_provider_short_name ON provider;
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'idx_provider_tenant_short_name')
+CREATE INDEX idx_provider_tenant_short_name ON provider (tenant_id, short_name);
@@ -0,0 +1,2 @@
+IF EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'uix_provider_tenant_short_name')
+DROP INDEX uix_provider_tenant_short_name ON provider;
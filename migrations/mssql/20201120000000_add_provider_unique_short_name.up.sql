@@ -0,0 +1,4 @@
+-- Short names are unique per tenant among live providers only, so soft deleted ones can be
+-- recreated.
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'uix_provider_tenant_short_name')
+CREATE UNIQUE INDEX uix_provider_tenant_short_name ON provider (tenant_id, short_name) WHERE deleted_at IS NULL;
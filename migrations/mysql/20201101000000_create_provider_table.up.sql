@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS provider (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  uuid VARCHAR(255) NOT NULL,
+  short_name VARCHAR(255) NOT NULL,
+  long_name VARCHAR(255) NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  deleted_at DATETIME NULL,
+  PRIMARY KEY (id),
+  UNIQUE INDEX uix_provider_uuid (uuid),
+  INDEX idx_provider_short_name (short_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
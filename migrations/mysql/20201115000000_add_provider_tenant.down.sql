@@ -0,0 +1,4 @@
+ALTER TABLE provider
+  DROP INDEX idx_provider_tenant_short_name,
+  DROP COLUMN tenant_id,
+  ADD INDEX idx_provider_short_name (short_name);
@@ -0,0 +1,4 @@
+ALTER TABLE provider
+  ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '' AFTER id,
+  DROP INDEX idx_provider_short_name,
+  ADD INDEX idx_provider_tenant_short_name (tenant_id, short_name);
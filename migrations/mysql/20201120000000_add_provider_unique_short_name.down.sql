@@ -0,0 +1,3 @@
+ALTER TABLE provider
+  DROP INDEX uix_provider_tenant_short_name,
+  DROP COLUMN live_short_name;
@@ -0,0 +1,6 @@
+-- Short names are unique per tenant among live providers only, so soft deleted ones can be
+-- recreated. MySQL has no partial indexes, so a generated column holding the short names of
+-- live providers is indexed instead, since unique indexes allow any number of NULLs.
+ALTER TABLE provider
+  ADD COLUMN live_short_name VARCHAR(255) AS (IF(deleted_at IS NULL, short_name, NULL)) STORED,
+  ADD UNIQUE INDEX uix_provider_tenant_short_name (tenant_id, live_short_name);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS provider (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL,
+  short_name VARCHAR(255) NOT NULL,
+  long_name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uix_provider_uuid ON provider (uuid);
+CREATE INDEX IF NOT EXISTS idx_provider_short_name ON provider (short_name);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_provider_tenant_short_name;
+ALTER TABLE provider DROP COLUMN tenant_id;
+CREATE INDEX IF NOT EXISTS idx_provider_short_name ON provider (short_name);
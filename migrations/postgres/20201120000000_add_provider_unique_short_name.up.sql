@@ -0,0 +1,3 @@
+-- Short names are unique per tenant among live providers only, so soft deleted ones can be
+-- recreated.
+CREATE UNIQUE INDEX IF NOT EXISTS uix_provider_tenant_short_name ON provider (tenant_id, short_name) WHERE deleted_at IS NULL;
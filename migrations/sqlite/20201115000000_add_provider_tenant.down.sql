@@ -0,0 +1,17 @@
+-- Older SQLite versions can't drop columns, so the table is rebuilt without tenant_id.
+DROP INDEX IF EXISTS idx_provider_tenant_short_name;
+CREATE TABLE provider_without_tenant (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid VARCHAR(255) NOT NULL,
+  short_name VARCHAR(255) NOT NULL,
+  long_name VARCHAR(255) NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  deleted_at DATETIME NULL
+);
+INSERT INTO provider_without_tenant (id, uuid, short_name, long_name, created_at, updated_at, deleted_at)
+SELECT id, uuid, short_name, long_name, created_at, updated_at, deleted_at FROM provider;
+DROP TABLE provider;
+ALTER TABLE provider_without_tenant RENAME TO provider;
+CREATE UNIQUE INDEX IF NOT EXISTS uix_provider_uuid ON provider (uuid);
+CREATE INDEX IF NOT EXISTS idx_provider_short_name ON provider (short_name);
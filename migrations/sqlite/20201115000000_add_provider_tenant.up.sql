@@ -0,0 +1,3 @@
+ALTER TABLE provider ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_provider_short_name;
+CREATE INDEX IF NOT EXISTS idx_provider_tenant_short_name ON provider (tenant_id, short_name);
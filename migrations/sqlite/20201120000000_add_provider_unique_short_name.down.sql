@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uix_provider_tenant_short_name;
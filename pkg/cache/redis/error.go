@@ -9,6 +9,12 @@ var (
 	ErrNoCache = errors.New("Cache not found")
 	// ErrFailedCommand represents a "Failed command" error.
 	ErrFailedCommand = errors.New("Failed command")
+	// ErrLockNotObtained represents a "Lock not obtained" error.
+	ErrLockNotObtained = errors.New("Lock not obtained")
+	// ErrLockNotHeld represents a "Lock not held" error.
+	ErrLockNotHeld = errors.New("Lock not held")
+	// ErrNotConnected represents a "Not connected" error.
+	ErrNotConnected = errors.New("Not connected")
 )
 
 // IsErrNoCache checks if the given error is a "Cache not found" error.
@@ -26,3 +32,13 @@ func IsErrFailedCommand(err error) bool {
 	}
 	return err == ErrFailedCommand
 }
+
+// IsErrLockNotObtained checks if the given error is a "Lock not obtained" error.
+func IsErrLockNotObtained(err error) bool {
+	return err == ErrLockNotObtained
+}
+
+// IsErrLockNotHeld checks if the given error is a "Lock not held" error.
+func IsErrLockNotHeld(err error) bool {
+	return err == ErrLockNotHeld
+}
@@ -0,0 +1,166 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	redisv8 "github.com/go-redis/redis/v8"
+)
+
+var (
+	// DefaultLockRetryInterval is the delay between attempts when waiting for a held lock.
+	DefaultLockRetryInterval = 100 * time.Millisecond
+	// lockClockDriftFactor compensates the lock validity time for clock drift between
+	// the client & Redis server, as described in the Redlock algorithm.
+	lockClockDriftFactor = 0.01
+)
+
+// Lua scripts make the token check & the write a single atomic operation, so a client
+// never extends or releases a lock that has already expired and been taken by another one.
+var (
+	releaseLockScript = redisv8.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+	extendLockScript = redisv8.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// Lock represents a distributed lock held on a Redis key.
+type Lock struct {
+	conn     *Connection
+	key      string
+	token    string
+	validity time.Time
+}
+
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func lockValidity(start time.Time, ttl time.Duration) time.Time {
+	drift := time.Duration(float64(ttl)*lockClockDriftFactor) + 2*time.Millisecond
+	return start.Add(ttl - drift)
+}
+
+// ObtainLock acquires a lock on the specified key which expires after the given TTL.
+// When the lock is held by another client, it keeps retrying until the lock is acquired,
+// the wait duration elapses, or the context is done. A zero wait tries only once. It returns
+// ErrLockNotObtained when the lock is still held once the wait is over, the context error
+// when the context is done first, and ErrNotConnected when Redis isn't connected.
+func (c *Connection) ObtainLock(
+	ctx context.Context, key string, ttl, wait time.Duration,
+) (*Lock, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	lockKey := c.namespacedKey(key)
+
+	var deadline <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		start := time.Now()
+
+		ok, err := c.Client.SetNX(ctx, lockKey, token, ttl).Result()
+		if err != nil {
+			c.LogError(err, fmt.Sprintf("Failed obtaining lock: '%s'", lockKey))
+			return nil, err
+		}
+
+		if ok {
+			return &Lock{
+				conn:     c,
+				key:      lockKey,
+				token:    token,
+				validity: lockValidity(start, ttl),
+			}, nil
+		}
+
+		if deadline == nil {
+			return nil, ErrLockNotObtained
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, ErrLockNotObtained
+		case <-time.After(DefaultLockRetryInterval):
+		}
+	}
+}
+
+// Key returns the namespaced key of the lock.
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns the unique value identifying the owner of the lock.
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Valid checks whether the lock is still safely held based on its local validity time.
+func (l *Lock) Valid() bool {
+	return time.Now().Before(l.validity)
+}
+
+// Extend renews the lock TTL as long as it's still held by this lock owner.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	start := time.Now()
+
+	res, err := extendLockScript.Run(
+		ctx, l.conn.Client, []string{l.key}, l.token, ttl.Milliseconds(),
+	).Int64()
+	if err != nil {
+		l.conn.LogError(err, fmt.Sprintf("Failed extending lock: '%s'", l.key))
+		return err
+	}
+
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+
+	l.validity = lockValidity(start, ttl)
+
+	return nil
+}
+
+// Release releases the lock as long as it's still held by this lock owner.
+func (l *Lock) Release(ctx context.Context) error {
+	res, err := releaseLockScript.Run(ctx, l.conn.Client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		l.conn.LogError(err, fmt.Sprintf("Failed releasing lock: '%s'", l.key))
+		return err
+	}
+
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
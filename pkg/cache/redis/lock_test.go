@@ -0,0 +1,185 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// testConnection connects to the Redis server at REDIS_TEST_ADDR with the optional
+// REDIS_TEST_PASSWORD, skipping the test when it isn't set. Keys are namespaced by the
+// test name, so tests don't share them.
+func testConnection(t *testing.T) *Connection {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR isn't set")
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid REDIS_TEST_ADDR: %v", err)
+	}
+
+	conn, err := NewConnection(context.Background(), RedisConfig{
+		Host:      host,
+		Port:      port,
+		Password:  os.Getenv("REDIS_TEST_PASSWORD"),
+		Namespace: fmt.Sprintf("test:%s:%d", t.Name(), time.Now().UnixNano()),
+	})
+	if err != nil {
+		t.Fatalf("failed connecting to Redis: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.DeleteCacheByPrefix(context.Background(), "*")
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func TestObtainLockNotConnected(t *testing.T) {
+	var conn *Connection
+
+	if _, err := conn.ObtainLock(context.Background(), "key", time.Second, 0); err != ErrNotConnected {
+		t.Fatalf("expected ErrNotConnected, got %v", err)
+	}
+}
+
+func TestLockValidity(t *testing.T) {
+	start := time.Now()
+	ttl := 10 * time.Second
+
+	validity := lockValidity(start, ttl)
+	if !validity.Before(start.Add(ttl)) {
+		t.Fatalf("expected validity before the TTL, got %v", validity.Sub(start))
+	}
+	if validity.Before(start.Add(ttl - time.Second)) {
+		t.Fatalf("expected drift under a second, got %v", start.Add(ttl).Sub(validity))
+	}
+}
+
+func TestObtainLock(t *testing.T) {
+	conn := testConnection(t)
+	ctx := context.Background()
+
+	lock, err := conn.ObtainLock(ctx, "lock", time.Second, 0)
+	if err != nil {
+		t.Fatalf("failed obtaining lock: %v", err)
+	}
+	if !lock.Valid() {
+		t.Fatal("expected obtained lock to be valid")
+	}
+	if lock.Token() == "" {
+		t.Fatal("expected obtained lock to have a token")
+	}
+
+	if _, err := conn.ObtainLock(ctx, "lock", time.Second, 0); err != ErrLockNotObtained {
+		t.Fatalf("expected ErrLockNotObtained without wait, got %v", err)
+	}
+
+	start := time.Now()
+	if _, err := conn.ObtainLock(ctx, "lock", time.Second, 200*time.Millisecond); err != ErrLockNotObtained {
+		t.Fatalf("expected ErrLockNotObtained after wait, got %v", err)
+	}
+	if waited := time.Since(start); waited < 200*time.Millisecond {
+		t.Fatalf("expected to wait for the lock, waited %v", waited)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := conn.ObtainLock(cancelled, "lock", time.Second, time.Second); err == nil {
+		t.Fatal("expected cancelled context to fail obtaining lock")
+	}
+}
+
+func TestObtainLockAfterExpiry(t *testing.T) {
+	conn := testConnection(t)
+	ctx := context.Background()
+
+	if _, err := conn.ObtainLock(ctx, "lock", 100*time.Millisecond, 0); err != nil {
+		t.Fatalf("failed obtaining lock: %v", err)
+	}
+
+	if _, err := conn.ObtainLock(ctx, "lock", time.Second, time.Second); err != nil {
+		t.Fatalf("expected lock to be obtained once expired, got %v", err)
+	}
+}
+
+func TestLockExtend(t *testing.T) {
+	conn := testConnection(t)
+	ctx := context.Background()
+
+	lock, err := conn.ObtainLock(ctx, "lock", 200*time.Millisecond, 0)
+	if err != nil {
+		t.Fatalf("failed obtaining lock: %v", err)
+	}
+
+	if err := lock.Extend(ctx, time.Second); err != nil {
+		t.Fatalf("failed extending lock: %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	if !lock.Valid() {
+		t.Fatal("expected extended lock to be valid")
+	}
+	if _, err := conn.ObtainLock(ctx, "lock", time.Second, 0); err != ErrLockNotObtained {
+		t.Fatalf("expected extended lock to be held, got %v", err)
+	}
+}
+
+func TestLockRelease(t *testing.T) {
+	conn := testConnection(t)
+	ctx := context.Background()
+
+	lock, err := conn.ObtainLock(ctx, "lock", time.Second, 0)
+	if err != nil {
+		t.Fatalf("failed obtaining lock: %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("failed releasing lock: %v", err)
+	}
+	if err := lock.Release(ctx); err != ErrLockNotHeld {
+		t.Fatalf("expected ErrLockNotHeld releasing twice, got %v", err)
+	}
+	if err := lock.Extend(ctx, time.Second); err != ErrLockNotHeld {
+		t.Fatalf("expected ErrLockNotHeld extending released lock, got %v", err)
+	}
+
+	if _, err := conn.ObtainLock(ctx, "lock", time.Second, 0); err != nil {
+		t.Fatalf("expected released lock to be obtained, got %v", err)
+	}
+}
+
+func TestLockTokenCheck(t *testing.T) {
+	conn := testConnection(t)
+	ctx := context.Background()
+
+	lock, err := conn.ObtainLock(ctx, "lock", time.Second, 0)
+	if err != nil {
+		t.Fatalf("failed obtaining lock: %v", err)
+	}
+
+	// Another owner of the same key, e.g. one whose lock has expired meanwhile.
+	other := &Lock{conn: conn, key: lock.Key(), token: "other", validity: time.Now().Add(time.Second)}
+
+	if err := other.Extend(ctx, time.Second); err != ErrLockNotHeld {
+		t.Fatalf("expected ErrLockNotHeld extending with another token, got %v", err)
+	}
+	if err := other.Release(ctx); err != ErrLockNotHeld {
+		t.Fatalf("expected ErrLockNotHeld releasing with another token, got %v", err)
+	}
+
+	if _, err := conn.ObtainLock(ctx, "lock", time.Second, 0); err != ErrLockNotObtained {
+		t.Fatalf("expected lock to be kept by its owner, got %v", err)
+	}
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("failed releasing lock: %v", err)
+	}
+}
@@ -3,11 +3,13 @@ package redis
 import (
 	"context"
 	"fmt"
+	"path"
 	"time"
 
 	cachev8 "github.com/go-redis/cache/v8"
 	redisv8 "github.com/go-redis/redis/v8"
 	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/retry"
 )
 
 var (
@@ -31,10 +33,13 @@ type RedisConfig struct {
 	Namespace string
 	DBNumber  int
 	DebugMode bool
+	// Retry configures retries of the initial connection, e.g. while Redis starts.
+	Retry retry.Config
 }
 
-// NewConnection creates new basic Redis connection.
-func NewConnection(conf RedisConfig) (*Connection, error) {
+// NewConnection creates new basic Redis connection, retrying as configured until
+// the context is done.
+func NewConnection(ctx context.Context, conf RedisConfig) (*Connection, error) {
 	client := redisv8.NewClient(&redisv8.Options{
 		Addr:     fmt.Sprintf("%s:%s", conf.Host, conf.Port),
 		Username: conf.Username,
@@ -52,14 +57,33 @@ func NewConnection(conf RedisConfig) (*Connection, error) {
 		DebugMode: conf.DebugMode,
 	}
 
-	if _, err := connection.Client.Ping(context.Background()).Result(); err != nil {
+	if err := retry.Do(ctx, conf.Retry, func() error {
+		return connection.Client.Ping(ctx).Err()
+	}, func(attempt int, err error, wait time.Duration) {
+		log.Stdout().Warn().
+			Timestamp().
+			Str("redisHost", client.Options().Addr).
+			Int("attempt", attempt).
+			Dur("retryIn", wait).
+			Err(err).
+			Msg("Redis warning: Failed connecting, retrying")
+	}); err != nil {
 		connection.LogError(err, "")
+		_ = client.Close()
 		return nil, err
 	}
 
 	return &connection, nil
 }
 
+// Check pings the Redis server, implementing health.Checker.
+func (c *Connection) Check(ctx context.Context) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+	return c.Client.Ping(ctx).Err()
+}
+
 // LogError prints Redis connection error log to stderr.
 func (c *Connection) LogError(err error, msg string) {
 	printMsg := "Redis error"
@@ -88,6 +112,14 @@ func (c *Connection) LogWarn(err error, msg string) {
 		Msg(printMsg)
 }
 
+// LogInfo prints Redis connection info log to stdout.
+func (c *Connection) LogInfo(msg string) {
+	log.Stdout().Info().
+		Timestamp().
+		Str("redisHost", c.Client.Options().Addr).
+		Msg(fmt.Sprintf("Redis info: %s", msg))
+}
+
 func (c *Connection) namespacedKey(key string) string {
 	return fmt.Sprintf("%s:%s", c.namespace, key)
 }
@@ -133,11 +165,15 @@ func (c *Connection) DeleteCache(ctx context.Context, key string) error {
 	return nil
 }
 
-// DeleteCacheByPrefix deletes multiple caches that matched the given prefix key.
-func (c *Connection) DeleteCacheByPrefix(ctx context.Context, prefix string) error {
+// DeleteCacheByPrefix deletes multiple caches that matched the given prefix key, except
+// the ones matching any of the excluded patterns, e.g. locks sharing the prefix.
+func (c *Connection) DeleteCacheByPrefix(ctx context.Context, prefix string, excludes ...string) error {
 	iter := c.Client.Scan(ctx, 0, c.namespacedKey(prefix), 0).Iterator()
 
 	for iter.Next(ctx) {
+		if c.isExcluded(iter.Val(), excludes) {
+			continue
+		}
 		if err := c.cache.Delete(ctx, iter.Val()); err != nil {
 			c.LogError(err, "")
 			return err
@@ -152,6 +188,26 @@ func (c *Connection) DeleteCacheByPrefix(ctx context.Context, prefix string) err
 	return nil
 }
 
+func (c *Connection) isExcluded(key string, excludes []string) bool {
+	for _, pattern := range excludes {
+		if ok, _ := path.Match(c.namespacedKey(pattern), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RunScript runs a Lua script against the namespaced version of the specified keys.
+func (c *Connection) RunScript(
+	ctx context.Context, script *redisv8.Script, keys []string, args ...interface{},
+) *redisv8.Cmd {
+	namespacedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		namespacedKeys[i] = c.namespacedKey(key)
+	}
+	return script.Run(ctx, c.Client, namespacedKeys, args...)
+}
+
 // Close closes the client, releasing any open resources.
 func (c *Connection) Close() error {
 	return c.Client.Close()
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Command represents a subcommand of a CLI application.
+type Command struct {
+	Name  string
+	Usage string
+	Run   func(args []string) error
+}
+
+// App dispatches command line arguments to its subcommands.
+type App struct {
+	Name     string
+	Default  string
+	Commands []Command
+}
+
+// Usage returns the help text listing all subcommands.
+func (a *App) Usage() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s <command> [arguments]\n\nCommands:\n", a.Name)
+	for _, c := range a.Commands {
+		fmt.Fprintf(&b, "  %-10s %s\n", c.Name, c.Usage)
+	}
+	return b.String()
+}
+
+// Run runs the subcommand named by the first argument, or the default subcommand
+// when no argument is given.
+func (a *App) Run(args []string) error {
+	name := a.Default
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprint(os.Stdout, a.Usage())
+		return nil
+	}
+
+	for _, c := range a.Commands {
+		if c.Name == name {
+			return c.Run(args)
+		}
+	}
+
+	return fmt.Errorf("unknown command: %s\n\n%s", name, a.Usage())
+}
@@ -0,0 +1,195 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math/rand"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jinzhu/gorm"
+	"github.com/rs/zerolog"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+)
+
+var (
+	// DefaultSlowQueryThreshold is the duration after which a query is logged as slow.
+	DefaultSlowQueryThreshold = 200 * time.Millisecond
+	// maxArgLength is the length after which string query arguments are truncated.
+	maxArgLength = 64
+)
+
+// LoggerConfig stores the configs of SQL query logging.
+type LoggerConfig struct {
+	// LogQueries logs every query at debug level, not only slow & failed ones.
+	LogQueries bool
+	// SlowQueryThreshold is the duration after which a query is logged at warn level.
+	SlowQueryThreshold time.Duration
+	// SampleRate is the fraction of queries, between 0 and 1, logged when LogQueries is set.
+	// Slow queries are always logged.
+	SampleRate float64
+	// RequestIDKey is the context key of the request ID added to query logs.
+	RequestIDKey string
+}
+
+// queryLogger adapts gorm logs to structured logs of pkg/log.
+// It implements gorm's logger interface.
+type queryLogger struct {
+	conf LoggerConfig
+	conn *Connection
+	ctx  context.Context
+}
+
+// SetLogger replaces gorm's unstructured logs of the connection & its replicas with
+// structured query logs.
+func (c *Connection) SetLogger(conf LoggerConfig) {
+	if conf.SlowQueryThreshold <= 0 {
+		conf.SlowQueryThreshold = DefaultSlowQueryThreshold
+	}
+	if conf.SampleRate <= 0 || conf.SampleRate > 1 {
+		conf.SampleRate = 1
+	}
+
+	c.loggerConfig = &conf
+
+	// gorm only passes SQL logs to its logger in log mode, the queries are filtered by
+	// the logger instead.
+	c.DB.LogMode(true)
+	c.DB.SetLogger(&queryLogger{conf: conf, conn: c})
+
+	for _, r := range c.replicas {
+		r.conn.SetLogger(conf)
+	}
+}
+
+// withContext returns a copy of the database whose query logs carry the request ID
+// found in the given context.
+func (c *Connection) withContext(ctx context.Context) *gorm.DB {
+	if c.loggerConfig == nil || ctx == nil {
+		return c.DB
+	}
+	db := c.DB.New()
+	db.SetLogger(&queryLogger{conf: *c.loggerConfig, conn: c, ctx: ctx})
+	return db
+}
+
+func (l *queryLogger) sample() bool {
+	if l.conf.SampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < l.conf.SampleRate
+}
+
+func (l *queryLogger) requestID() string {
+	if l.ctx == nil || l.conf.RequestIDKey == "" {
+		return ""
+	}
+	id, _ := l.ctx.Value(l.conf.RequestIDKey).(string)
+	return id
+}
+
+// Print receives gorm logs. SQL logs are made of "sql", the source, the duration,
+// the query, its arguments & the number of affected rows.
+func (l *queryLogger) Print(values ...interface{}) {
+	if len(values) < 2 {
+		return
+	}
+
+	if values[0] != "sql" || len(values) < 6 {
+		l.printMessage(values...)
+		return
+	}
+
+	duration, _ := values[2].(time.Duration)
+	query, _ := values[3].(string)
+	args, _ := values[4].([]interface{})
+	rows, _ := values[5].(int64)
+
+	var event *zerolog.Event
+	switch {
+	case duration >= l.conf.SlowQueryThreshold:
+		event = log.Stdout().Warn()
+	case l.conf.LogQueries && l.sample():
+		event = log.Stdout().Debug()
+	default:
+		return
+	}
+
+	msg := fmt.Sprintf("%s query", l.conn.dialect)
+	if duration >= l.conf.SlowQueryThreshold {
+		msg = fmt.Sprintf("%s slow query", l.conn.dialect)
+	}
+
+	event.
+		Timestamp().
+		Str("dbHost", l.conn.address).
+		Str("requestID", l.requestID()).
+		Str("source", fmt.Sprint(values[1])).
+		Str("query", query).
+		Interface("args", sanitizeArgs(args)).
+		Int64("rows", rows).
+		Dur("duration", duration).
+		Msg(msg)
+}
+
+// printMessage logs non-SQL gorm logs, which are mostly errors.
+func (l *queryLogger) printMessage(values ...interface{}) {
+	msg := fmt.Sprintf("%s log", l.conn.dialect)
+	for _, v := range values[2:] {
+		if err, ok := v.(error); ok {
+			log.Stderr().Error().
+				Timestamp().
+				Str("dbHost", l.conn.address).
+				Str("requestID", l.requestID()).
+				Str("source", fmt.Sprint(values[1])).
+				Err(err).
+				Msg(msg)
+			return
+		}
+	}
+
+	log.Stdout().Info().
+		Timestamp().
+		Str("dbHost", l.conn.address).
+		Str("requestID", l.requestID()).
+		Str("source", fmt.Sprint(values[1])).
+		Msg(fmt.Sprint(values[2:]...))
+}
+
+// sanitizeArgs keeps query arguments readable without dumping long texts or binary data.
+func sanitizeArgs(args []interface{}) []interface{} {
+	sanitized := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		if valuer, ok := arg.(driver.Valuer); ok {
+			if v, err := valuer.Value(); err == nil {
+				arg = v
+			}
+		}
+
+		switch v := arg.(type) {
+		case nil:
+			sanitized = append(sanitized, "NULL")
+		case []byte:
+			sanitized = append(sanitized, fmt.Sprintf("<%d bytes>", len(v)))
+		case string:
+			if utf8.RuneCountInString(v) > maxArgLength {
+				v = fmt.Sprintf("%s...", string([]rune(v)[:maxArgLength]))
+			}
+			sanitized = append(sanitized, v)
+		case *time.Time:
+			if v == nil {
+				sanitized = append(sanitized, "NULL")
+			} else {
+				sanitized = append(sanitized, v.Format(time.RFC3339Nano))
+			}
+		case time.Time:
+			sanitized = append(sanitized, v.Format(time.RFC3339Nano))
+		default:
+			sanitized = append(sanitized, v)
+		}
+	}
+
+	return sanitized
+}
@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"context"
+	dbsql "database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+)
+
+// ErrLockTimeout occurs when another process holds the migrations lock for too long.
+var ErrLockTimeout = errors.New("Timed out waiting for migrations lock")
+
+func placeholder(dialect string, n int) string {
+	switch dialect {
+	case sql.DialectPostgres:
+		return fmt.Sprintf("$%d", n)
+	case sql.DialectMSSQL:
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return "?"
+	}
+}
+
+func createTableQuery(dialect, table string) string {
+	switch dialect {
+	case sql.DialectMSSQL:
+		return fmt.Sprintf(`IF OBJECT_ID(N'%[1]s', N'U') IS NULL
+CREATE TABLE %[1]s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name NVARCHAR(255) NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at DATETIME2 NOT NULL
+)`, table)
+	case sql.DialectPostgres:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+)`, table)
+	default:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at DATETIME NOT NULL
+)`, table)
+	}
+}
+
+func tableExistsQuery(dialect string) string {
+	switch dialect {
+	case sql.DialectMySQL:
+		return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case sql.DialectPostgres:
+		return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	case sql.DialectMSSQL:
+		return "SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_NAME = @p1"
+	default:
+		return "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	}
+}
+
+// lockID derives the numeric lock identifier required by PostgreSQL advisory locks.
+func lockID(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64() >> 1)
+}
+
+// acquireLock takes a session level lock on the given connection, so the lock is released
+// by the database even when the process dies mid-migration.
+func acquireLock(
+	ctx context.Context, conn *dbsql.Conn, dialect, name string, timeout time.Duration,
+) error {
+	switch dialect {
+	case sql.DialectMySQL:
+		var res dbsql.NullInt64
+		if err := conn.QueryRowContext(
+			ctx, "SELECT GET_LOCK(?, ?)", name, int(timeout.Seconds()),
+		).Scan(&res); err != nil {
+			return err
+		}
+		if !res.Valid || res.Int64 != 1 {
+			return ErrLockTimeout
+		}
+	case sql.DialectPostgres:
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID(name)); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return ErrLockTimeout
+			}
+			return err
+		}
+	case sql.DialectMSSQL:
+		var res int
+		if err := conn.QueryRowContext(ctx, `DECLARE @result INT;
+EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = @p2;
+SELECT @result`, name, timeout.Milliseconds()).Scan(&res); err != nil {
+			return err
+		}
+		if res < 0 {
+			return ErrLockTimeout
+		}
+	}
+	// SQLite serializes writers with its own database file lock.
+	return nil
+}
+
+func releaseLock(ctx context.Context, conn *dbsql.Conn, dialect, name string) error {
+	var err error
+	switch dialect {
+	case sql.DialectMySQL:
+		_, err = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+	case sql.DialectPostgres:
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID(name))
+	case sql.DialectMSSQL:
+		_, err = conn.ExecContext(
+			ctx, "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", name,
+		)
+	}
+	return err
+}
@@ -0,0 +1,372 @@
+// Package migrate applies versioned SQL schema migrations stored as ordered up & down
+// script files, one directory per SQL dialect.
+//
+// Each migration runs in a transaction along with its record in the migrations table, so a
+// failing migration is rolled back entirely. MySQL commits DDL statements implicitly
+// though, which would leave a failing multi-statement migration half applied & unrecorded,
+// so MySQL scripts must hold a single statement. Several changes of one table still fit in
+// a single ALTER TABLE statement.
+//
+// Concurrent migrators wait for each other through a database lock, except on SQLite which
+// has none. SQLite only serializes their transactions, so a migrator applying a migration
+// applied by another one meanwhile fails & must be run again.
+package migrate
+
+import (
+	"context"
+	dbsql "database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+)
+
+var (
+	// DefaultTable is the table storing applied migrations.
+	DefaultTable = "schema_migrations"
+	// DefaultLockTimeout is how long to wait for another process running migrations.
+	DefaultLockTimeout = 1 * time.Minute
+)
+
+// Config stores migrator configurations.
+type Config struct {
+	// Dir is the root directory of the migrations, containing a directory per dialect.
+	Dir         string
+	Table       string
+	LockTimeout time.Duration
+}
+
+// Migrator applies migrations to a SQL database.
+type Migrator struct {
+	conn        *sql.Connection
+	dir         string
+	table       string
+	lockTimeout time.Duration
+}
+
+// Status represents the state of a migration in the database.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified tells whether the up script changed after the migration was applied.
+	Modified bool
+	// Missing tells whether the migration is applied but its scripts no longer exist.
+	Missing bool
+}
+
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// New creates new migrator for the given SQL database connection.
+func New(conn *sql.Connection, conf Config) (*Migrator, error) {
+	dialectDir, err := DialectDir(conn.Dialect())
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Dir == "" {
+		conf.Dir = "migrations"
+	}
+	if conf.Table == "" {
+		conf.Table = DefaultTable
+	}
+	if conf.LockTimeout <= 0 {
+		conf.LockTimeout = DefaultLockTimeout
+	}
+
+	return &Migrator{
+		conn:        conn,
+		dir:         filepath.Join(conf.Dir, dialectDir),
+		table:       conf.Table,
+		lockTimeout: conf.LockTimeout,
+	}, nil
+}
+
+// Dir returns the directory of the migrations for the database dialect.
+func (m *Migrator) Dir() string {
+	return m.dir
+}
+
+// withLock runs fn on a dedicated database connection while holding the migrations lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *dbsql.Conn) error) error {
+	conn, err := m.conn.DB.DB().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockName := fmt.Sprintf("%s_lock", m.table)
+	if err := acquireLock(ctx, conn, m.conn.Dialect(), lockName, m.lockTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		if err := releaseLock(context.Background(), conn, m.conn.Dialect(), lockName); err != nil {
+			m.conn.LogWarn(err, "Failed releasing migrations lock")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createTableQuery(m.conn.Dialect(), m.table)); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *dbsql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		"SELECT version, name, checksum, applied_at FROM %s ORDER BY version", m.table,
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[a.version] = a
+	}
+
+	return applied, rows.Err()
+}
+
+// verify ensures applied migrations haven't been edited since, as the database schema
+// would no longer match what the scripts describe, and that the database can run their
+// scripts atomically.
+func verify(dialect string, migrations []Migration, applied map[int64]appliedMigration) error {
+	for _, mig := range migrations {
+		if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+			return fmt.Errorf(
+				"checksum mismatch for applied migration %d_%s: script was modified", mig.Version, mig.Name,
+			)
+		}
+
+		if dialect != sql.DialectMySQL {
+			continue
+		}
+		for _, script := range []string{mig.Up, mig.Down} {
+			if n := len(splitStatements(script)); n > 1 {
+				return fmt.Errorf(
+					"migration %d_%s has a script of %d statements, MySQL migrations must have "+
+						"one statement per script as MySQL commits DDL statements implicitly",
+					mig.Version, mig.Name, n,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) run(
+	ctx context.Context, conn *dbsql.Conn, mig Migration, script string, record func(tx *dbsql.Tx) error,
+) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d_%s failed: %v", mig.Version, mig.Name, err)
+		}
+	}
+
+	if err := record(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Up applies pending migrations in order. Non-positive steps apply all of them.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	d := m.conn.Dialect()
+
+	err = m.withLock(ctx, func(conn *dbsql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err := verify(d, migrations, applied); err != nil {
+			return err
+		}
+
+		for _, mig := range migrations {
+			if steps > 0 && len(done) >= steps {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+
+			mig := mig
+			if err := m.run(ctx, conn, mig, mig.Up, func(tx *dbsql.Tx) error {
+				_, err := tx.ExecContext(ctx, fmt.Sprintf(
+					"INSERT INTO %s (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+					m.table, placeholder(d, 1), placeholder(d, 2), placeholder(d, 3), placeholder(d, 4),
+				), mig.Version, mig.Name, mig.Checksum, time.Now().UTC())
+				return err
+			}); err != nil {
+				return err
+			}
+
+			log.Info(fmt.Sprintf("Applied migration %d_%s", mig.Version, mig.Name))
+			done = append(done, mig)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts applied migrations, latest first. Non-positive steps revert one migration.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	d := m.conn.Dialect()
+
+	err = m.withLock(ctx, func(conn *dbsql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err := verify(d, migrations, applied); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+
+			if mig.Down == "" {
+				return fmt.Errorf("missing down script for migration %d_%s", mig.Version, mig.Name)
+			}
+
+			if err := m.run(ctx, conn, mig, mig.Down, func(tx *dbsql.Tx) error {
+				_, err := tx.ExecContext(ctx, fmt.Sprintf(
+					"DELETE FROM %s WHERE version = %s", m.table, placeholder(d, 1),
+				), mig.Version)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			log.Info(fmt.Sprintf("Reverted migration %d_%s", mig.Version, mig.Name))
+			done = append(done, mig)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// withoutLock runs fn on a dedicated database connection without the migrations lock nor
+// creating the migrations table, for read-only access. The applied migrations are empty
+// when the table doesn't exist yet.
+func (m *Migrator) withoutLock(
+	ctx context.Context, fn func(applied map[int64]appliedMigration) error,
+) error {
+	conn, err := m.conn.DB.DB().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var count int
+	if err := conn.QueryRowContext(
+		ctx, tableExistsQuery(m.conn.Dialect()), m.table,
+	).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return fn(map[int64]appliedMigration{})
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(applied)
+}
+
+// Status lists all known migrations along with their state in the database. It only reads
+// the database, so it neither waits for running migrations nor creates the migrations table.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []Status{}
+
+	err = m.withoutLock(ctx, func(applied map[int64]appliedMigration) error {
+
+		known := map[int64]struct{}{}
+		for _, mig := range migrations {
+			known[mig.Version] = struct{}{}
+			s := Status{Migration: mig}
+			if a, ok := applied[mig.Version]; ok {
+				appliedAt := a.appliedAt
+				s.Applied = true
+				s.AppliedAt = &appliedAt
+				s.Modified = a.checksum != mig.Checksum
+			}
+			statuses = append(statuses, s)
+		}
+
+		for _, a := range applied {
+			if _, ok := known[a.version]; ok {
+				continue
+			}
+			appliedAt := a.appliedAt
+			statuses = append(statuses, Status{
+				Migration: Migration{Version: a.version, Name: a.name, Checksum: a.checksum},
+				Applied:   true,
+				AppliedAt: &appliedAt,
+				Missing:   true,
+			})
+		}
+
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].Version < statuses[j].Version
+		})
+
+		return nil
+	})
+
+	return statuses, err
+}
@@ -0,0 +1,381 @@
+package migrate
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql/sqlite"
+)
+
+// tempDir creates a directory removed once the test is done.
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return dir
+}
+
+// writeScripts writes the given scripts, by file name, into the directory.
+func writeScripts(t *testing.T, dir string, scripts map[string]string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed creating %s: %v", dir, err)
+	}
+	for name, script := range scripts {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0644); err != nil {
+			t.Fatalf("failed writing %s: %v", name, err)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"empty", "", []string{}},
+		{"single without semicolon", "SELECT 1", []string{"SELECT 1"}},
+		{"multiple", "SELECT 1;\nSELECT 2;\n", []string{"SELECT 1", "SELECT 2"}},
+		{"empty statements", ";; SELECT 1 ;;", []string{"SELECT 1"}},
+		{"single quotes", "SELECT 'a;b'; SELECT 2", []string{"SELECT 'a;b'", "SELECT 2"}},
+		{"escaped quotes", "SELECT 'it''s;'; SELECT 2", []string{"SELECT 'it''s;'", "SELECT 2"}},
+		{"double quotes", `SELECT ";" AS "a;b"`, []string{`SELECT ";" AS "a;b"`}},
+		{"backticks", "SELECT 1 AS `a;b`", []string{"SELECT 1 AS `a;b`"}},
+		{"line comments", "-- a; b\nSELECT 1; -- c;\nSELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"comments only", "-- a;\n-- b\n", []string{}},
+		{"dashes in quotes", "SELECT '--'; SELECT 2", []string{"SELECT '--'", "SELECT 2"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := tempDir(t)
+	writeScripts(t, dir, map[string]string{
+		"2_second.up.sql":   "CREATE TABLE b (id INTEGER);",
+		"2_second.down.sql": "DROP TABLE b;",
+		"1_first.up.sql":    "CREATE TABLE a (id INTEGER);",
+		"README.md":         "ignored",
+		"3_Invalid.up.sql":  "ignored",
+	})
+
+	migrations, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed loading migrations: %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "first" || migrations[0].Down != "" {
+		t.Errorf("unexpected first migration: %+v", migrations[0])
+	}
+	if migrations[1].Version != 2 || migrations[1].Name != "second" || migrations[1].Down != "DROP TABLE b;" {
+		t.Errorf("unexpected second migration: %+v", migrations[1])
+	}
+	if migrations[1].Checksum != checksum("CREATE TABLE b (id INTEGER);") {
+		t.Errorf("expected checksum of the up script, got %s", migrations[1].Checksum)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		scripts map[string]string
+		wantErr string
+	}{
+		{
+			"missing up script",
+			map[string]string{"1_first.down.sql": "DROP TABLE a;"},
+			"missing up script",
+		},
+		{
+			"conflicting names",
+			map[string]string{"1_first.up.sql": "", "1_other.down.sql": ""},
+			"conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		dir := tempDir(t)
+		writeScripts(t, dir, tt.scripts)
+
+		if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	if checksum("SELECT 1;") != checksum("SELECT 1;") {
+		t.Fatal("expected same scripts to have the same checksum")
+	}
+	if checksum("SELECT 1;") == checksum("SELECT 1; ") {
+		t.Fatal("expected different scripts to have different checksums")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	single := Migration{Version: 1, Name: "single", Up: "ALTER TABLE a ADD b INT;", Down: "ALTER TABLE a DROP b;"}
+	single.Checksum = checksum(single.Up)
+	multi := Migration{Version: 2, Name: "multi", Up: "CREATE TABLE b (id INT); CREATE TABLE c (id INT);"}
+	multi.Checksum = checksum(multi.Up)
+
+	tests := []struct {
+		name       string
+		dialect    string
+		migrations []Migration
+		applied    map[int64]appliedMigration
+		wantErr    string
+	}{
+		{"pending", sql.DialectMySQL, []Migration{single}, nil, ""},
+		{"applied", sql.DialectMySQL, []Migration{single}, map[int64]appliedMigration{
+			1: {version: 1, checksum: single.Checksum},
+		}, ""},
+		{"modified", sql.DialectSQLite, []Migration{single}, map[int64]appliedMigration{
+			1: {version: 1, checksum: checksum("ALTER TABLE a ADD c INT;")},
+		}, "checksum mismatch"},
+		{"multiple statements", sql.DialectSQLite, []Migration{multi}, nil, ""},
+		{"multiple statements on MySQL", sql.DialectMySQL, []Migration{multi}, nil, "2 statements"},
+	}
+
+	for _, tt := range tests {
+		err := verify(tt.dialect, tt.migrations, tt.applied)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+// testMigrator creates a migrator of a new SQLite database, with its migrations stored in
+// the returned directory.
+func testMigrator(t *testing.T) (*Migrator, *sql.Connection, string) {
+	t.Helper()
+
+	dir := tempDir(t)
+
+	conn, err := sqlite.NewConnection(context.Background(), sql.DBConfig{
+		Database: filepath.Join(dir, "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("failed opening SQLite database: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.DB.Close() })
+
+	m, err := New(conn, Config{Dir: dir, LockTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("failed creating migrator: %v", err)
+	}
+
+	return m, conn, m.Dir()
+}
+
+func versions(migrations []Migration) []int64 {
+	vs := []int64{}
+	for _, m := range migrations {
+		vs = append(vs, m.Version)
+	}
+	return vs
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	m, conn, dir := testMigrator(t)
+
+	writeScripts(t, dir, map[string]string{
+		"1_create_a.up.sql":   "CREATE TABLE a (id INTEGER PRIMARY KEY);",
+		"1_create_a.down.sql": "DROP TABLE a;",
+		"2_create_b.up.sql":   "CREATE TABLE b (id INTEGER PRIMARY KEY);\nINSERT INTO b (id) VALUES (1);",
+		"2_create_b.down.sql": "DROP TABLE b;",
+		"3_create_c.up.sql":   "CREATE TABLE c (id INTEGER PRIMARY KEY);",
+		"3_create_c.down.sql": "DROP TABLE c;",
+	})
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("failed getting status: %v", err)
+	}
+	if len(statuses) != 3 || statuses[0].Applied {
+		t.Fatalf("expected 3 pending migrations, got %+v", statuses)
+	}
+	if conn.DB.HasTable(DefaultTable) {
+		t.Fatal("expected status not to create the migrations table")
+	}
+
+	done, err := m.Up(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed applying migrations: %v", err)
+	}
+	if got := versions(done); !reflect.DeepEqual(got, []int64{1}) {
+		t.Fatalf("expected migration 1 to be applied, got %v", got)
+	}
+
+	done, err = m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("failed applying migrations: %v", err)
+	}
+	if got := versions(done); !reflect.DeepEqual(got, []int64{2, 3}) {
+		t.Fatalf("expected migrations 2 & 3 to be applied, got %v", got)
+	}
+	for _, table := range []string{"a", "b", "c"} {
+		if !conn.DB.HasTable(table) {
+			t.Fatalf("expected table %s to exist", table)
+		}
+	}
+
+	if done, err = m.Up(ctx, 0); err != nil || len(done) != 0 {
+		t.Fatalf("expected nothing to apply, got %v, %v", versions(done), err)
+	}
+
+	done, err = m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("failed reverting migrations: %v", err)
+	}
+	if got := versions(done); !reflect.DeepEqual(got, []int64{3, 2}) {
+		t.Fatalf("expected migrations 3 & 2 to be reverted, got %v", got)
+	}
+	if conn.DB.HasTable("b") || conn.DB.HasTable("c") || !conn.DB.HasTable("a") {
+		t.Fatal("expected only table a to exist")
+	}
+
+	statuses, err = m.Status(ctx)
+	if err != nil {
+		t.Fatalf("failed getting status: %v", err)
+	}
+	applied := []bool{}
+	for _, s := range statuses {
+		applied = append(applied, s.Applied)
+	}
+	if !reflect.DeepEqual(applied, []bool{true, false, false}) {
+		t.Fatalf("expected only migration 1 to be applied, got %v", applied)
+	}
+}
+
+func TestUpFailureIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	m, conn, dir := testMigrator(t)
+
+	writeScripts(t, dir, map[string]string{
+		"1_create_a.up.sql": "CREATE TABLE a (id INTEGER PRIMARY KEY);",
+		"2_broken.up.sql":   "CREATE TABLE b (id INTEGER PRIMARY KEY);\nINSERT INTO missing (id) VALUES (1);",
+	})
+
+	done, err := m.Up(ctx, 0)
+	if err == nil || !strings.Contains(err.Error(), "migration 2_broken failed") {
+		t.Fatalf("expected migration 2 to fail, got %v", err)
+	}
+	if got := versions(done); !reflect.DeepEqual(got, []int64{1}) {
+		t.Fatalf("expected only migration 1 to be applied, got %v", got)
+	}
+	if conn.DB.HasTable("b") {
+		t.Fatal("expected the failing migration to be rolled back")
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("failed getting status: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Fatalf("expected only migration 1 to be recorded, got %+v", statuses)
+	}
+}
+
+func TestModifiedMigration(t *testing.T) {
+	ctx := context.Background()
+	m, _, dir := testMigrator(t)
+
+	writeScripts(t, dir, map[string]string{
+		"1_create_a.up.sql": "CREATE TABLE a (id INTEGER PRIMARY KEY);",
+	})
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatalf("failed applying migrations: %v", err)
+	}
+
+	writeScripts(t, dir, map[string]string{
+		"1_create_a.up.sql": "CREATE TABLE a (id INTEGER PRIMARY KEY, name TEXT);",
+	})
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("failed getting status: %v", err)
+	}
+	if !statuses[0].Modified {
+		t.Fatal("expected status to report the modified migration")
+	}
+
+	if _, err := m.Up(ctx, 0); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := m.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestDownWithoutScript(t *testing.T) {
+	ctx := context.Background()
+	m, _, dir := testMigrator(t)
+
+	writeScripts(t, dir, map[string]string{
+		"1_create_a.up.sql": "CREATE TABLE a (id INTEGER PRIMARY KEY);",
+	})
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatalf("failed applying migrations: %v", err)
+	}
+
+	if _, err := m.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "missing down script") {
+		t.Fatalf("expected missing down script, got %v", err)
+	}
+}
+
+func TestRepositoryMigrations(t *testing.T) {
+	ctx := context.Background()
+	dir := tempDir(t)
+
+	conn, err := sqlite.NewConnection(ctx, sql.DBConfig{Database: filepath.Join(dir, "test.db")})
+	if err != nil {
+		t.Fatalf("failed opening SQLite database: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.DB.Close() })
+
+	m, err := New(conn, Config{Dir: filepath.Join("..", "..", "..", "..", "migrations")})
+	if err != nil {
+		t.Fatalf("failed creating migrator: %v", err)
+	}
+
+	up, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("failed applying migrations: %v", err)
+	}
+	if len(up) == 0 {
+		t.Fatal("expected migrations to be applied")
+	}
+
+	down, err := m.Down(ctx, len(up))
+	if err != nil {
+		t.Fatalf("failed reverting migrations: %v", err)
+	}
+	if len(down) != len(up) {
+		t.Fatalf("expected %d migrations to be reverted, got %d", len(up), len(down))
+	}
+}
@@ -0,0 +1,187 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+)
+
+const versionFormat = "20060102150405"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration represents a versioned schema change with its up & down SQL scripts.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// DialectDir returns the name of the directory storing migrations of the given dialect.
+func DialectDir(dialect string) (string, error) {
+	switch dialect {
+	case sql.DialectMySQL:
+		return "mysql", nil
+	case sql.DialectPostgres:
+		return "postgres", nil
+	case sql.DialectMSSQL:
+		return "mssql", nil
+	case sql.DialectSQLite:
+		return "sqlite", nil
+	default:
+		return "", fmt.Errorf("unsupported sql dialect: %s", dialect)
+	}
+}
+
+func checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads all migrations from a directory, ordered by their version.
+func Load(dir string) ([]Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(f.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("conflicting names for migration %d: %s, %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+			m.Checksum = checksum(m.Up)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("missing up script for migration %d_%s", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Create writes empty up & down scripts of a new migration into each given directory.
+func Create(name string, dirs ...string) ([]string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name: %s", name)
+	}
+
+	version := time.Now().UTC().Format(versionFormat)
+	paths := []string{}
+
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+			if err := ioutil.WriteFile(path, []byte{}, 0644); err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// splitStatements splits a script into statements on semicolons outside of quotes &
+// comments, since not every driver accepts multiple statements in one query.
+func splitStatements(script string) []string {
+	statements := []string{}
+
+	var (
+		current     strings.Builder
+		quote       rune
+		lineComment bool
+	)
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case lineComment:
+			if r == '\n' {
+				lineComment = false
+			}
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			lineComment = true
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ';':
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			current.Reset()
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+
+	return statements
+}
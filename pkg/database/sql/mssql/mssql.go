@@ -1,18 +1,26 @@
 package mssql
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
-	"github.com/jinzhu/gorm"
+	mssqldb "github.com/denisenkom/go-mssqldb"
 	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
 
 	// Import Microsoft SQL Server driver.
 	_ "github.com/jinzhu/gorm/dialects/mssql"
 )
 
+func init() {
+	sql.Register("mssql", NewConnection)
+}
+
 // NewConnection creates a new connection to a Microsoft SQL Server database using provided
-// connection configs.
-func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
+// connection configs, retrying as configured until the context is done.
+// Paginated queries must be ordered, since SQL Server only accepts OFFSET & FETCH after an
+// ORDER BY clause.
+func NewConnection(ctx context.Context, conf sql.DBConfig) (*sql.Connection, error) {
 	dsn := fmt.Sprintf(
 		"sqlserver://%s:%s@%s:%s?database=%s&%s",
 		conf.Username,
@@ -23,7 +31,7 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 		conf.Params,
 	)
 
-	db, err := gorm.Open("mssql", dsn)
+	db, err := sql.Dial(ctx, conf, sql.DialectMSSQL, "mssql", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -34,5 +42,15 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 	db.SingularTable(conf.SingularTable)
 	db.LogMode(conf.DebugMode)
 
-	return sql.NewConnection(db, conf.Host, conf.Port, "MSSQL"), nil
+	conn := sql.NewConnection(db, conf.Host, conf.Port, sql.DialectMSSQL)
+	conn.SetDuplicateKeyFunc(isDuplicateKey)
+
+	return conn, nil
+}
+
+// isDuplicateKey tells whether an error is a SQL Server duplicate key error, violating
+// either a unique index or a unique constraint.
+func isDuplicateKey(err error) bool {
+	var mssqlErr mssqldb.Error
+	return errors.As(err, &mssqlErr) && (mssqlErr.Number == 2601 || mssqlErr.Number == 2627)
 }
@@ -1,18 +1,24 @@
 package mysql
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
-	"github.com/jinzhu/gorm"
+	driver "github.com/go-sql-driver/mysql"
 	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
 
 	// Import MySQL driver.
 	_ "github.com/jinzhu/gorm/dialects/mysql"
 )
 
+func init() {
+	sql.Register("mysql", NewConnection)
+}
+
 // NewConnection creates a new connection to a MySQL database using provided
-// connection configs.
-func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
+// connection configs, retrying as configured until the context is done.
+func NewConnection(ctx context.Context, conf sql.DBConfig) (*sql.Connection, error) {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%s)/%s?%s",
 		conf.Username,
@@ -23,7 +29,7 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 		conf.Params,
 	)
 
-	db, err := gorm.Open("mysql", dsn)
+	db, err := sql.Dial(ctx, conf, sql.DialectMySQL, "mysql", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -34,5 +40,14 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 	db.SingularTable(conf.SingularTable)
 	db.LogMode(conf.DebugMode)
 
-	return sql.NewConnection(db, conf.Host, conf.Port, "MySQL"), nil
+	conn := sql.NewConnection(db, conf.Host, conf.Port, sql.DialectMySQL)
+	conn.SetDuplicateKeyFunc(isDuplicateKey)
+
+	return conn, nil
+}
+
+// isDuplicateKey tells whether an error is a MySQL duplicate entry error.
+func isDuplicateKey(err error) bool {
+	var mysqlErr *driver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
 }
@@ -1,18 +1,24 @@
 package postgres
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
-	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
 	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
 
 	// Import PostgreSQL driver.
 	_ "github.com/jinzhu/gorm/dialects/postgres"
 )
 
+func init() {
+	sql.Register("postgres", NewConnection)
+}
+
 // NewConnection creates a new connection to a PostgreSQL database using provided
-// connection configs.
-func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
+// connection configs, retrying as configured until the context is done.
+func NewConnection(ctx context.Context, conf sql.DBConfig) (*sql.Connection, error) {
 	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?%s",
 		conf.Username,
@@ -23,7 +29,7 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 		conf.Params,
 	)
 
-	db, err := gorm.Open("postgres", dsn)
+	db, err := sql.Dial(ctx, conf, sql.DialectPostgres, "postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -34,5 +40,14 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 	db.SingularTable(conf.SingularTable)
 	db.LogMode(conf.DebugMode)
 
-	return sql.NewConnection(db, conf.Host, conf.Port, "PostgreSQL"), nil
+	conn := sql.NewConnection(db, conf.Host, conf.Port, sql.DialectPostgres)
+	conn.SetDuplicateKeyFunc(isDuplicateKey)
+
+	return conn, nil
+}
+
+// isDuplicateKey tells whether an error is a PostgreSQL unique violation error.
+func isDuplicateKey(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
 }
@@ -0,0 +1,202 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ReplicaPolicy decides which healthy replica serves a read query.
+type ReplicaPolicy string
+
+const (
+	// RoundRobin spreads read queries evenly across replicas.
+	RoundRobin ReplicaPolicy = "round-robin"
+	// LeastConnections sends read queries to the replica with the fewest connections in use.
+	LeastConnections ReplicaPolicy = "least-connections"
+)
+
+var (
+	// DefaultReplicaCheckInterval is how often replicas are pinged to check their health.
+	DefaultReplicaCheckInterval = 10 * time.Second
+	// DefaultReadYourWritesWindow is how long reads stay on the primary after a write.
+	DefaultReadYourWritesWindow = 5 * time.Second
+)
+
+// SessionKey is the context key of the session tracking writes done while serving a request.
+// It's a string so it can also be stored in the keys of a gin context.
+const SessionKey = "sqlSession"
+
+// ParseReplicaPolicy parses a replica policy name: round-robin or least-connections.
+func ParseReplicaPolicy(name string) (ReplicaPolicy, error) {
+	switch p := ReplicaPolicy(strings.ToLower(name)); p {
+	case RoundRobin, LeastConnections:
+		return p, nil
+	case "":
+		return RoundRobin, nil
+	default:
+		return "", fmt.Errorf("unsupported replica policy: %s", name)
+	}
+}
+
+// Session records the last write done while serving a request, so reads following it
+// can see its result despite the replication lag.
+type Session struct {
+	lastWrite int64
+}
+
+// NewSession creates new session.
+func NewSession() *Session {
+	return &Session{}
+}
+
+// WithSession returns a copy of the context carrying a new session.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, SessionKey, NewSession())
+}
+
+func sessionFrom(ctx context.Context) *Session {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(SessionKey).(*Session)
+	return s
+}
+
+func (s *Session) markWrite() {
+	atomic.StoreInt64(&s.lastWrite, time.Now().UnixNano())
+}
+
+func (s *Session) wroteWithin(window time.Duration) bool {
+	lastWrite := atomic.LoadInt64(&s.lastWrite)
+	return lastWrite > 0 && time.Since(time.Unix(0, lastWrite)) < window
+}
+
+type replica struct {
+	conn    *Connection
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// setHealthy stores the health state of the replica & tells whether it changed.
+func (r *replica) setHealthy(healthy bool) bool {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	return atomic.SwapInt32(&r.healthy, v) != v
+}
+
+// ReplicaConfig stores the configs of read replica routing.
+type ReplicaConfig struct {
+	Policy ReplicaPolicy
+	// ReadYourWritesWindow is how long reads of a session stay on the primary after a write.
+	ReadYourWritesWindow time.Duration
+	CheckInterval        time.Duration
+}
+
+// SetReplicas makes the connection route read queries to the given replicas.
+// Replicas are health checked in the background until the connection is closed.
+func (c *Connection) SetReplicas(conf ReplicaConfig, replicas ...*Connection) {
+	if conf.Policy == "" {
+		conf.Policy = RoundRobin
+	}
+	if conf.ReadYourWritesWindow <= 0 {
+		conf.ReadYourWritesWindow = DefaultReadYourWritesWindow
+	}
+	if conf.CheckInterval <= 0 {
+		conf.CheckInterval = DefaultReplicaCheckInterval
+	}
+
+	c.replicaConfig = conf
+	c.replicas = []*replica{}
+	for _, conn := range replicas {
+		c.replicas = append(c.replicas, &replica{conn: conn, healthy: 1})
+	}
+
+	if len(c.replicas) > 0 {
+		c.stopCheck = make(chan struct{})
+		go c.checkReplicas(c.stopCheck)
+	}
+}
+
+func (c *Connection) checkReplicas(stop chan struct{}) {
+	ticker := time.NewTicker(c.replicaConfig.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, r := range c.replicas {
+				ctx, cancel := context.WithTimeout(context.Background(), c.replicaConfig.CheckInterval)
+				err := r.conn.DB.DB().PingContext(ctx)
+				cancel()
+
+				if !r.setHealthy(err == nil) {
+					continue
+				}
+				if err != nil {
+					r.conn.LogWarn(err, "Replica is unhealthy, routing its reads to primary")
+				} else {
+					r.conn.LogWarn(nil, "Replica is healthy again")
+				}
+			}
+		}
+	}
+}
+
+// Writer returns the primary database for write queries & transactions, and marks
+// the session in the context as having written.
+func (c *Connection) Writer(ctx context.Context) *gorm.DB {
+	if s := sessionFrom(ctx); s != nil {
+		s.markWrite()
+	}
+	return c.withContext(ctx)
+}
+
+// Reader returns a database for read queries. It's a healthy replica picked by the
+// replica policy, or the primary when there's none or the session in the context has
+// written recently.
+func (c *Connection) Reader(ctx context.Context) *gorm.DB {
+	if len(c.replicas) == 0 {
+		return c.withContext(ctx)
+	}
+
+	if s := sessionFrom(ctx); s != nil && s.wroteWithin(c.replicaConfig.ReadYourWritesWindow) {
+		return c.withContext(ctx)
+	}
+
+	healthy := make([]*replica, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if r.isHealthy() {
+			healthy = append(healthy, r)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return c.withContext(ctx)
+	}
+
+	if c.replicaConfig.Policy == LeastConnections {
+		picked := healthy[0]
+		inUse := picked.conn.DB.DB().Stats().InUse
+		for _, r := range healthy[1:] {
+			if n := r.conn.DB.DB().Stats().InUse; n < inUse {
+				picked, inUse = r, n
+			}
+		}
+		return picked.conn.withContext(ctx)
+	}
+
+	n := atomic.AddUint32(&c.next, 1)
+	return healthy[int(n-1)%len(healthy)].conn.withContext(ctx)
+}
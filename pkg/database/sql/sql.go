@@ -1,11 +1,24 @@
 package sql
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/retry"
+)
+
+// List of supported SQL database dialects.
+const (
+	DialectMySQL    = "MySQL"
+	DialectPostgres = "PostgreSQL"
+	DialectMSSQL    = "MSSQL"
+	DialectSQLite   = "SQLite"
 )
 
 // DBConfig stores SQL database common connection config.
@@ -20,18 +33,172 @@ type DBConfig struct {
 	MaxOpenConns  int
 	SingularTable bool
 	DebugMode     bool
+	// Replicas are read replicas of the database, only connected to by Open.
+	Replicas      []DBConfig
+	ReplicaConfig ReplicaConfig
+	// Logger enables structured query logs, set up by Open.
+	Logger *LoggerConfig
+	// Retry configures retries of the initial connection, e.g. while the database starts.
+	Retry retry.Config
+}
+
+// Opener creates a new connection to a SQL database using provided connection configs.
+type Opener func(ctx context.Context, conf DBConfig) (*Connection, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = map[string]Opener{}
+)
+
+// Register makes a SQL database dialect available by the provided driver name.
+// It's meant to be called from the init function of each dialect package.
+func Register(driver string, opener Opener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+
+	if opener == nil {
+		panic("sql: register opener is nil")
+	}
+	if _, dup := openers[driver]; dup {
+		panic(fmt.Sprintf("sql: register called twice for driver %s", driver))
+	}
+	openers[driver] = opener
+}
+
+// Drivers returns a sorted list of the names of the registered drivers.
+func Drivers() []string {
+	openersMu.RLock()
+	defer openersMu.RUnlock()
+
+	drivers := []string{}
+	for driver := range openers {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
+
+// Open creates a new connection to a SQL database using the dialect registered under
+// the given driver name. The dialect package must be imported for its driver to be registered.
+// Read queries are routed to the configured replicas, see Connection.Reader.
+func Open(ctx context.Context, driver string, conf DBConfig) (*Connection, error) {
+	openersMu.RLock()
+	opener, ok := openers[strings.ToLower(driver)]
+	openersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported sql driver: %s (forgotten import?)", driver)
+	}
+
+	conn, err := opener(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := []*Connection{}
+	for _, rconf := range conf.Replicas {
+		replica, err := opener(ctx, rconf)
+		if err != nil {
+			for _, r := range replicas {
+				_ = r.Close()
+			}
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed connecting to replica %s:%s: %v", rconf.Host, rconf.Port, err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	if len(replicas) > 0 {
+		conn.SetReplicas(conf.ReplicaConfig, replicas...)
+	}
+
+	if conf.Logger != nil {
+		conn.SetLogger(*conf.Logger)
+	}
+
+	return conn, nil
+}
+
+// Dial opens a database with gorm, retrying with backoff as configured in the connection
+// configs until the context is done. It's meant to be used by dialect packages.
+func Dial(ctx context.Context, conf DBConfig, dialect, driver, dsn string) (*gorm.DB, error) {
+	var db *gorm.DB
+
+	err := retry.Do(ctx, conf.Retry, func() error {
+		var err error
+		db, err = gorm.Open(driver, dsn)
+		return err
+	}, func(attempt int, err error, wait time.Duration) {
+		log.Stdout().Warn().
+			Timestamp().
+			Str(fmt.Sprintf("%sHost", strings.ToLower(dialect)), fmt.Sprintf("%s:%s", conf.Host, conf.Port)).
+			Int("attempt", attempt).
+			Dur("retryIn", wait).
+			Err(err).
+			Msg(fmt.Sprintf("%s warning: Failed connecting, retrying", dialect))
+	})
+
+	return db, err
 }
 
 // Connection stores SQL database connection client & information.
 type Connection struct {
+	// DB is the primary database. Prefer Reader & Writer which take replicas into account.
 	DB      *gorm.DB
 	address string
 	dialect string
+
+	replicas      []*replica
+	replicaConfig ReplicaConfig
+	next          uint32
+	stopCheck     chan struct{}
+
+	loggerConfig *LoggerConfig
+
+	isDuplicateKey DuplicateKeyFunc
 }
 
+// DuplicateKeyFunc tells whether an error of a query is a violation of a unique index.
+type DuplicateKeyFunc func(err error) bool
+
 // NewConnection creates new SQL database connection.
 func NewConnection(DB *gorm.DB, host, port, dialect string) *Connection {
-	return &Connection{DB, fmt.Sprintf("%s:%s", host, port), dialect}
+	return &Connection{DB: DB, address: fmt.Sprintf("%s:%s", host, port), dialect: dialect}
+}
+
+// SetDuplicateKeyFunc sets how the connection recognizes unique index violations, which
+// differ with each driver. It's meant to be called by dialect packages.
+func (c *Connection) SetDuplicateKeyFunc(f DuplicateKeyFunc) {
+	c.isDuplicateKey = f
+}
+
+// IsDuplicateKey tells whether an error of a query is a violation of a unique index.
+func (c *Connection) IsDuplicateKey(err error) bool {
+	return err != nil && c.isDuplicateKey != nil && c.isDuplicateKey(err)
+}
+
+// Dialect returns the SQL dialect of the database connection.
+func (c *Connection) Dialect() string {
+	return c.dialect
+}
+
+// Address returns the host:port address of the database.
+func (c *Connection) Address() string {
+	return c.address
+}
+
+// Replicas returns the connections to the read replicas of the database.
+func (c *Connection) Replicas() []*Connection {
+	replicas := []*Connection{}
+	for _, r := range c.replicas {
+		replicas = append(replicas, r.conn)
+	}
+	return replicas
+}
+
+// Check pings the primary database, implementing health.Checker.
+func (c *Connection) Check(ctx context.Context) error {
+	return c.DB.DB().PingContext(ctx)
 }
 
 // LogError prints SQL database connection error log to stderr.
@@ -62,7 +229,18 @@ func (c *Connection) LogWarn(err error, msg string) {
 		Msg(printMsg)
 }
 
-// Close closes current db connection.
+// Close closes current db connection along with its replicas.
 func (c *Connection) Close() error {
+	if c.stopCheck != nil {
+		close(c.stopCheck)
+		c.stopCheck = nil
+	}
+
+	for _, r := range c.replicas {
+		if err := r.conn.Close(); err != nil {
+			c.LogWarn(err, fmt.Sprintf("Failed closing replica %s", r.conn.address))
+		}
+	}
+
 	return c.DB.Close()
 }
@@ -1,17 +1,24 @@
 package sqlite
 
 import (
-	"github.com/jinzhu/gorm"
+	"context"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
 	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
 
 	// Import SQLite driver.
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
 )
 
+func init() {
+	sql.Register("sqlite", NewConnection)
+}
+
 // NewConnection creates a new connection to an SQLite database using provided
-// connection configs.
-func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
-	db, err := gorm.Open("sqlite3", conf.Database)
+// connection configs, retrying as configured until the context is done.
+func NewConnection(ctx context.Context, conf sql.DBConfig) (*sql.Connection, error) {
+	db, err := sql.Dial(ctx, conf, sql.DialectSQLite, "sqlite3", conf.Database)
 	if err != nil {
 		return nil, err
 	}
@@ -22,5 +29,14 @@ func NewConnection(conf sql.DBConfig) (*sql.Connection, error) {
 	db.SingularTable(conf.SingularTable)
 	db.LogMode(conf.DebugMode)
 
-	return sql.NewConnection(db, conf.Host, conf.Port, "SQLite"), nil
+	conn := sql.NewConnection(db, conf.Host, conf.Port, sql.DialectSQLite)
+	conn.SetDuplicateKeyFunc(isDuplicateKey)
+
+	return conn, nil
+}
+
+// isDuplicateKey tells whether an error is an SQLite unique constraint error.
+func isDuplicateKey(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
 }
@@ -0,0 +1,163 @@
+// Package health keeps a registry of dependency checkers and reports whether the
+// application is ready to serve traffic.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// List of check statuses.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+var (
+	// DefaultCacheTTL is how long a check result is reused before checking again.
+	DefaultCacheTTL = 5 * time.Second
+	// DefaultTimeout is how long a single check may take.
+	DefaultTimeout = 2 * time.Second
+)
+
+// Checker checks the availability of a dependency.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to use ordinary functions as checkers.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the outcome of a dependency check.
+type Result struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Latency   string    `json:"latency"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the outcome of checking all registered dependencies.
+// The application is ready when all critical dependencies are up.
+type Report struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	critical bool
+
+	mu        sync.Mutex
+	result    Result
+	expiresAt time.Time
+}
+
+// Registry stores dependency checkers.
+type Registry struct {
+	mu       sync.RWMutex
+	checks   []*check
+	cacheTTL time.Duration
+	timeout  time.Duration
+}
+
+// NewRegistry creates new health check registry.
+func NewRegistry(cacheTTL, timeout time.Duration) *Registry {
+	if cacheTTL < 0 {
+		cacheTTL = DefaultCacheTTL
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Registry{
+		checks:   []*check{},
+		cacheTTL: cacheTTL,
+		timeout:  timeout,
+	}
+}
+
+// Register adds a dependency checker to the registry. The application isn't ready
+// while a critical dependency is down, while non-critical ones are only reported.
+func (r *Registry) Register(name string, checker Checker, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.checks {
+		if c.name == name {
+			r.checks[i] = &check{name: name, checker: checker, critical: critical}
+			return
+		}
+	}
+
+	r.checks = append(r.checks, &check{name: name, checker: checker, critical: critical})
+	sort.Slice(r.checks, func(i, j int) bool {
+		return r.checks[i].name < r.checks[j].name
+	})
+}
+
+func (r *Registry) run(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(c.expiresAt) {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := c.checker.Check(ctx)
+
+	c.result = Result{
+		Status:    StatusUp,
+		Critical:  c.critical,
+		Latency:   time.Since(now).String(),
+		CheckedAt: now,
+	}
+	if err != nil {
+		c.result.Status = StatusDown
+		c.result.Error = err.Error()
+	}
+	c.expiresAt = now.Add(r.cacheTTL)
+
+	return c.result
+}
+
+// Check runs all registered checkers concurrently, reusing results which haven't expired.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]*check, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Ready: true, Checks: map[string]Result{}}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if c.critical && results[i].Status != StatusUp {
+			report.Ready = false
+		}
+	}
+
+	return report
+}
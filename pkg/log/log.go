@@ -48,29 +48,52 @@ func init() {
 	logConfig := &config{}
 	envconfig.MustProcess("", logConfig)
 
-	var logLevel zerolog.Level
+	// An unsupported log level falls back to info, it's up to the application to validate
+	// its configurations & call Configure.
+	if err := ValidateLevel(logConfig.LogLevel); err != nil {
+		logConfig.LogLevel = LevelInfo
+	}
+
+	_ = Configure(logConfig.LogLevel, logConfig.LogAsJSON)
+}
 
-	switch strings.ToUpper(logConfig.LogLevel) {
+func parseLevel(level string) (zerolog.Level, error) {
+	switch strings.ToUpper(level) {
 	case LevelDebug:
-		logLevel = zerolog.DebugLevel
+		return zerolog.DebugLevel, nil
 	case LevelInfo:
-		logLevel = zerolog.InfoLevel
+		return zerolog.InfoLevel, nil
 	case LevelWarn:
-		logLevel = zerolog.WarnLevel
+		return zerolog.WarnLevel, nil
 	case LevelError:
-		logLevel = zerolog.ErrorLevel
+		return zerolog.ErrorLevel, nil
 	case LevelFatal:
-		logLevel = zerolog.FatalLevel
+		return zerolog.FatalLevel, nil
 	case LevelPanic:
-		logLevel = zerolog.PanicLevel
+		return zerolog.PanicLevel, nil
 	default:
-		panic(fmt.Errorf("unsupported log level: %s", logConfig.LogLevel))
+		return zerolog.NoLevel, fmt.Errorf("unsupported log level: %s", level)
+	}
+}
+
+// ValidateLevel returns an error when the given log level isn't supported.
+func ValidateLevel(level string) error {
+	_, err := parseLevel(level)
+	return err
+}
+
+// Configure replaces the loggers with ones using the given level & format.
+// It isn't safe to call it while logging from other goroutines.
+func Configure(level string, asJSON bool) error {
+	logLevel, err := parseLevel(level)
+	if err != nil {
+		return err
 	}
 
 	var stdErrWriter io.Writer
 	var stdOutWriter io.Writer
 
-	if logConfig.LogAsJSON {
+	if asJSON {
 		stdErrWriter = os.Stderr
 		stdOutWriter = os.Stdout
 	} else {
@@ -78,16 +101,50 @@ func init() {
 		stdOutWriter = formatConsoleWriter(os.Stdout)
 	}
 
+	// The level is global so it can be changed by SetLevel while logging, including for
+	// copies of the loggers held by other packages.
+	zerolog.SetGlobalLevel(logLevel)
+
 	singleton = &logger{
 		stderr: zerolog.New(stdErrWriter).
-			Level(logLevel).
 			With().
 			Logger(),
 		stdout: zerolog.New(stdOutWriter).
-			Level(logLevel).
 			With().
 			Logger(),
 	}
+
+	return nil
+}
+
+// SetLevel changes the log level of all loggers. It's safe to call it while logging.
+func SetLevel(level string) error {
+	logLevel, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(logLevel)
+	return nil
+}
+
+// Level returns the current log level, e.g. INFO.
+func Level() string {
+	switch zerolog.GlobalLevel() {
+	case zerolog.DebugLevel:
+		return LevelDebug
+	case zerolog.InfoLevel:
+		return LevelInfo
+	case zerolog.WarnLevel:
+		return LevelWarn
+	case zerolog.ErrorLevel:
+		return LevelError
+	case zerolog.FatalLevel:
+		return LevelFatal
+	case zerolog.PanicLevel:
+		return LevelPanic
+	default:
+		return strings.ToUpper(zerolog.GlobalLevel().String())
+	}
 }
 
 func formatConsoleWriter(out *os.File) zerolog.ConsoleWriter {
@@ -0,0 +1,98 @@
+// Package retry retries failing operations with exponential backoff and jitter.
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+var (
+	// DefaultInitialInterval is the wait after the first failed attempt.
+	DefaultInitialInterval = 500 * time.Millisecond
+	// DefaultMaxInterval caps the wait between two attempts.
+	DefaultMaxInterval = 10 * time.Second
+	// DefaultMultiplier grows the wait after each failed attempt.
+	DefaultMultiplier = 2.0
+	// DefaultJitter is the fraction of the wait which is randomized.
+	DefaultJitter = 0.2
+)
+
+// Config stores retry configurations.
+type Config struct {
+	// MaxWait is the total time spent retrying before giving up. Zero disables retries.
+	MaxWait         time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Jitter randomizes each wait by up to the given fraction, so instances started
+	// together don't retry in lockstep. Waits are always randomized: values outside of
+	// (0, 1], including zero, use DefaultJitter.
+	Jitter float64
+}
+
+// Notify is called after each failed attempt with the wait before the next one.
+type Notify func(attempt int, err error, wait time.Duration)
+
+func (c *Config) defaults() {
+	if c.InitialInterval <= 0 {
+		c.InitialInterval = DefaultInitialInterval
+	}
+	if c.MaxInterval <= 0 {
+		c.MaxInterval = DefaultMaxInterval
+	}
+	if c.Multiplier < 1 {
+		c.Multiplier = DefaultMultiplier
+	}
+	if c.Jitter <= 0 || c.Jitter > 1 {
+		c.Jitter = DefaultJitter
+	}
+}
+
+// Do calls fn until it succeeds, the max wait is exceeded or the context is done.
+// It returns the last error of fn, or the context error when the context is done first.
+func Do(ctx context.Context, conf Config, fn func() error, notify Notify) error {
+	conf.defaults()
+
+	deadline := time.Now().Add(conf.MaxWait)
+	interval := conf.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return err
+		}
+
+		delta := conf.Jitter * float64(interval)
+		wait := time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+		if wait > remaining {
+			wait = remaining
+		}
+
+		if notify != nil {
+			notify(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * conf.Multiplier)
+		if interval > conf.MaxInterval {
+			interval = conf.MaxInterval
+		}
+	}
+}
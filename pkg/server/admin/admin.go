@@ -0,0 +1,168 @@
+// Package admin serves operational endpoints, like profiling & runtime log level changes,
+// which must not be exposed on the public port.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/logger"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/requestid"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
+)
+
+// Server represents the implementation of admin server object.
+type Server struct {
+	http *http.Server
+	Port string
+	// Listen is the listener spec of the server, see listener.ParseSpec. Defaults to the
+	// TCP port of the server on the loopback interface.
+	Listen string
+	// Token protects the endpoints when set, requests must send it as a bearer token in the
+	// Authorization header.
+	Token       string
+	httpServers []*httpserver.Server
+	triggers    map[string]Trigger
+	endpoints   []route
+	listener    listener.Managed
+	mu          sync.Mutex
+}
+
+// Trigger is an operational action run on demand, like flushing a cache.
+type Trigger func(ctx context.Context) error
+
+type route struct {
+	method       string
+	relativePath string
+	handlers     []gin.HandlerFunc
+}
+
+// NewServer creates new admin server.
+func NewServer(port, token string) *Server {
+	if port == "" {
+		port = "9181"
+	}
+
+	return &Server{
+		Port:     port,
+		Token:    token,
+		triggers: map[string]Trigger{},
+	}
+}
+
+// AddHTTPServer registers an HTTP server whose routes are listed by the routes endpoint.
+func (s *Server) AddHTTPServer(servers ...*httpserver.Server) {
+	s.httpServers = append(s.httpServers, servers...)
+}
+
+// AddTrigger registers an action run by POST /triggers/:name.
+func (s *Server) AddTrigger(name string, trigger Trigger) {
+	s.triggers[name] = trigger
+}
+
+// GET registers an admin endpoint with Get method, protected like the built-in ones.
+func (s *Server) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	s.endpoints = append(s.endpoints, route{http.MethodGet, relativePath, handlers})
+}
+
+func (s *Server) router() *gin.Engine {
+	router := gin.New()
+	router.Use(
+		gin.Recovery(),
+		requestid.New(),
+		logger.New(s.Port),
+		s.authenticate,
+	)
+
+	pprof := router.Group("/debug/pprof")
+	pprof.GET("/*profile", profile)
+	pprof.POST("/symbol", profile)
+
+	router.GET("/routes", s.routes)
+	router.GET("/runtime/goroutines", goroutines)
+	router.GET("/runtime/heap", heap)
+	router.GET("/log/level", logLevel)
+	router.PUT("/log/level", setLogLevel)
+	router.GET("/triggers", s.listTriggers)
+	router.POST("/triggers/:name", s.runTrigger)
+
+	for _, r := range s.endpoints {
+		router.Handle(r.method, r.relativePath, r.handlers...)
+	}
+
+	return router
+}
+
+// authenticate rejects requests without the token, when the server has one.
+func (s *Server) authenticate(ctx *gin.Context) {
+	if s.Token == "" {
+		return
+	}
+
+	auth := ctx.GetHeader("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+		ctx.Header("WWW-Authenticate", `Bearer realm="admin"`)
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "missing or invalid token",
+		})
+	}
+}
+
+// Start starts the admin server.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	srv := &http.Server{
+		Handler: s.router(),
+	}
+	s.http = srv
+	s.mu.Unlock()
+
+	spec := s.Listen
+	if spec == "" {
+		// Operational endpoints must not be exposed by default.
+		spec = net.JoinHostPort("127.0.0.1", s.Port)
+	}
+	l, err := s.listener.Listen(spec, s.Port, nil)
+	if err != nil || l == nil {
+		return err
+	}
+	if s.Token == "" {
+		log.Warn("Admin server has no token, anyone reaching its port can use it")
+	}
+	log.Info(fmt.Sprintf("Start admin server on %s", listener.Addr(l)))
+
+	return s.listener.Err(srv.Serve(l))
+}
+
+// Listening returns a channel which is closed once the server listens.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listener.Listening()
+}
+
+// Release stops accepting connections once the listener is handed off to another process,
+// while the accepted ones are still served until the server is stopped.
+func (s *Server) Release() error {
+	return s.listener.Release()
+}
+
+// Stop stops the admin server.
+func (s *Server) Stop(ctx context.Context) error {
+	log.Info(fmt.Sprintf("Stop admin server on port %s", s.Port))
+	s.listener.Stop()
+	s.mu.Lock()
+	srv := s.http
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+)
+
+// profile serves the net/http/pprof handlers, which find the requested profile from the
+// /debug/pprof/ prefix of the request path.
+func profile(ctx *gin.Context) {
+	switch strings.TrimPrefix(ctx.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(ctx.Writer, ctx.Request)
+	case "profile":
+		pprof.Profile(ctx.Writer, ctx.Request)
+	case "symbol":
+		pprof.Symbol(ctx.Writer, ctx.Request)
+	case "trace":
+		pprof.Trace(ctx.Writer, ctx.Request)
+	default:
+		pprof.Index(ctx.Writer, ctx.Request)
+	}
+}
+
+type serverRoutes struct {
+	Port   string                 `json:"port"`
+	Routes []httpserver.RouteInfo `json:"routes"`
+}
+
+// routes lists the routes of the registered HTTP servers.
+func (s *Server) routes(ctx *gin.Context) {
+	servers := []serverRoutes{}
+	for _, srv := range s.httpServers {
+		servers = append(servers, serverRoutes{
+			Port:   srv.Port,
+			Routes: srv.GetRoutes(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"servers": servers})
+}
+
+type goroutineGroup struct {
+	Count    int    `json:"count"`
+	State    string `json:"state"`
+	Function string `json:"function"`
+}
+
+// goroutines summarizes the running goroutines, grouped by their state & the function at
+// the top of their stacks.
+func goroutines(ctx *gin.Context) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	counts := map[goroutineGroup]int{}
+	total := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Buffer(make([]byte, 64*1024), len(buf))
+	for scanner.Scan() {
+		// Stacks start with a header like "goroutine 1 [chan receive, 5 minutes]:",
+		// followed by the top function.
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "goroutine ") {
+			continue
+		}
+		total++
+
+		group := goroutineGroup{}
+		if start, end := strings.Index(line, "["), strings.Index(line, "]"); start >= 0 && end > start {
+			group.State = strings.SplitN(line[start+1:end], ",", 2)[0]
+		}
+		if scanner.Scan() {
+			group.Function = scanner.Text()
+			if i := strings.LastIndex(group.Function, "("); i > 0 {
+				group.Function = group.Function[:i]
+			}
+		}
+		counts[group]++
+	}
+
+	groups := make([]goroutineGroup, 0, len(counts))
+	for group, count := range counts {
+		group.Count = count
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Function < groups[j].Function
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"total":  total,
+		"groups": groups,
+	})
+}
+
+// heap summarizes the memory stats of the heap & the garbage collector.
+func heap(ctx *gin.Context) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	lastGC := ""
+	if stats.LastGC > 0 {
+		lastGC = time.Unix(0, int64(stats.LastGC)).UTC().Format(time.RFC3339Nano)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"heapAllocBytes":    stats.HeapAlloc,
+		"heapInuseBytes":    stats.HeapInuse,
+		"heapIdleBytes":     stats.HeapIdle,
+		"heapReleasedBytes": stats.HeapReleased,
+		"heapSysBytes":      stats.HeapSys,
+		"heapObjects":       stats.HeapObjects,
+		"totalAllocBytes":   stats.TotalAlloc,
+		"sysBytes":          stats.Sys,
+		"mallocs":           stats.Mallocs,
+		"frees":             stats.Frees,
+		"nextGCBytes":       stats.NextGC,
+		"lastGC":            lastGC,
+		"numGC":             stats.NumGC,
+		"gcPauseTotal":      time.Duration(stats.PauseTotalNs).String(),
+		"gcCPUFraction":     stats.GCCPUFraction,
+	})
+}
+
+func logLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"level": log.Level()})
+}
+
+type setLogLevelReq struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// setLogLevel changes the log level until the process restarts, or until LOG_LEVEL is
+// changed in the reloaded config.
+func setLogLevel(ctx *gin.Context) {
+	var req setLogLevelReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := log.Level()
+	if err := log.SetLevel(req.Level); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Info(fmt.Sprintf("Log level changed from %s to %s through the admin server", previous, log.Level()))
+	ctx.JSON(http.StatusOK, gin.H{"level": log.Level(), "previous": previous})
+}
+
+func (s *Server) listTriggers(ctx *gin.Context) {
+	names := make([]string, 0, len(s.triggers))
+	for name := range s.triggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx.JSON(http.StatusOK, gin.H{"triggers": names})
+}
+
+func (s *Server) runTrigger(ctx *gin.Context) {
+	name := ctx.Param("name")
+	trigger, ok := s.triggers[name]
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("unknown trigger: %s", name)})
+		return
+	}
+
+	start := time.Now()
+	if err := trigger(ctx.Request.Context()); err != nil {
+		_ = ctx.Error(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"trigger": name, "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"trigger":  name,
+		"duration": time.Since(start).String(),
+	})
+}
@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/health"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server represents the implementation of gRPC server object.
+type Server struct {
+	grpc *grpc.Server
+	Port string
+	// Listen is the listener spec of the server, see listener.ParseSpec. Defaults to the
+	// TCP port of the server.
+	Listen string
+	// ProxyProtocol reads the client addresses from PROXY protocol headers when set.
+	ProxyProtocol *listener.ProxyConfig
+	// EnableReflection registers the reflection service, which lets clients like grpcurl
+	// list the services & their messages.
+	EnableReflection bool
+	// Health stores the dependency checkers reported by the health service.
+	Health             *health.Registry
+	services           []service
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	listener           listener.Managed
+	mu                 sync.Mutex
+	draining           int32
+	drained            chan struct{}
+	drainOnce          sync.Once
+}
+
+type service struct {
+	desc *grpc.ServiceDesc
+	impl interface{}
+}
+
+// NewServer creates new gRPC server.
+func NewServer(port string, enableReflection bool) *Server {
+	if port == "" {
+		port = "9090"
+	}
+
+	return &Server{
+		Port:             port,
+		EnableReflection: enableReflection,
+		Health:           health.NewRegistry(health.DefaultCacheTTL, health.DefaultTimeout),
+		drained:          make(chan struct{}),
+	}
+}
+
+// RegisterService registers a service & its implementation to the gRPC server, so the
+// server can be passed to the generated RegisterXServer functions.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	s.services = append(s.services, service{desc: desc, impl: impl})
+}
+
+// AddUnaryInterceptor adds an interceptor of unary calls to the gRPC server.
+func (s *Server) AddUnaryInterceptor(i grpc.UnaryServerInterceptor) {
+	s.unaryInterceptors = append(s.unaryInterceptors, i)
+}
+
+// AddStreamInterceptor adds an interceptor of streaming calls to the gRPC server.
+func (s *Server) AddStreamInterceptor(i grpc.StreamServerInterceptor) {
+	s.streamInterceptors = append(s.streamInterceptors, i)
+}
+
+// GetServiceNames retrieves the names of all services registered to this gRPC server.
+func (s *Server) GetServiceNames() []string {
+	names := []string{}
+	for _, svc := range s.services {
+		names = append(names, svc.desc.ServiceName)
+	}
+	return names
+}
+
+// Start starts the gRPC server.
+func (s *Server) Start() error {
+	// The request ID & logger interceptors wrap the added ones, so they see the status
+	// codes reported by them. Panics are recovered closest to the handlers, so the other
+	// interceptors see them as internal errors.
+	unary := []grpc.UnaryServerInterceptor{unaryRequestID(), unaryLogger(s.Port)}
+	unary = append(unary, s.unaryInterceptors...)
+	unary = append(unary, unaryRecovery())
+
+	stream := []grpc.StreamServerInterceptor{streamRequestID(), streamLogger(s.Port)}
+	stream = append(stream, s.streamInterceptors...)
+	stream = append(stream, streamRecovery())
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	for _, svc := range s.services {
+		srv.RegisterService(svc.desc, svc.impl)
+	}
+	healthpb.RegisterHealthServer(srv, &healthServer{server: s})
+	if s.EnableReflection {
+		reflection.Register(srv)
+	}
+	s.mu.Lock()
+	s.grpc = srv
+	s.mu.Unlock()
+
+	l, err := s.listener.Listen(s.Listen, s.Port, s.ProxyProtocol)
+	if err != nil || l == nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Start gRPC server on %s", listener.Addr(l)))
+
+	return s.listener.Err(srv.Serve(l))
+}
+
+// Drain makes the health service report the server as not serving, so load balancers
+// stop sending it new calls before it's stopped. Health watches are ended, as they'd
+// otherwise keep the server from stopping gracefully.
+func (s *Server) Drain() {
+	atomic.StoreInt32(&s.draining, 1)
+	s.drainOnce.Do(func() { close(s.drained) })
+}
+
+// Listening returns a channel which is closed once the server listens.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listener.Listening()
+}
+
+// Release stops accepting connections once the listener is handed off to another process,
+// while the accepted ones are still served until the server is stopped.
+func (s *Server) Release() error {
+	return s.listener.Release()
+}
+
+// Stop stops the gRPC server gracefully, waiting for the pending calls to finish. The
+// remaining calls are cancelled when the context is done first.
+func (s *Server) Stop(ctx context.Context) error {
+	s.listener.Stop()
+	s.Drain()
+	s.mu.Lock()
+	srv := s.grpc
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	log.Info(fmt.Sprintf("Stop gRPC server on port %s", s.Port))
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthWatchInterval is how often the status is checked for watching clients.
+var healthWatchInterval = 5 * time.Second
+
+// healthServer implements the standard gRPC health checking protocol, see
+// https://github.com/grpc/grpc/blob/master/doc/health-checking.md. The server & all its
+// services are reported as serving while every critical dependency is up.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	server *Server
+}
+
+func (h *healthServer) status(ctx context.Context, name string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	if name != "" && !h.server.hasService(name) {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Errorf(codes.NotFound, "unknown service: %s", name)
+	}
+
+	if atomic.LoadInt32(&h.server.draining) == 1 {
+		return healthpb.HealthCheckResponse_NOT_SERVING, nil
+	}
+
+	if !h.server.Health.Check(ctx).Ready {
+		return healthpb.HealthCheckResponse_NOT_SERVING, nil
+	}
+
+	return healthpb.HealthCheckResponse_SERVING, nil
+}
+
+// Check reports the status of the server, or of one of its services.
+func (h *healthServer) Check(
+	ctx context.Context, req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	s, err := h.status(ctx, req.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: s}, nil
+}
+
+// Watch streams the status of the server, or of one of its services, whenever it changes.
+// Unknown services are reported as such instead of failing the call. Once the server is
+// drained, it reports NOT_SERVING & ends the watch.
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		s, _ := h.status(stream.Context(), req.GetService())
+		if s != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: s}); err != nil {
+				return err
+			}
+			last = s
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-h.server.drained:
+			if last != healthpb.HealthCheckResponse_NOT_SERVING {
+				return stream.Send(&healthpb.HealthCheckResponse{
+					Status: healthpb.HealthCheckResponse_NOT_SERVING,
+				})
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) hasService(name string) bool {
+	for _, svc := range s.services {
+		if svc.desc.ServiceName == name {
+			return true
+		}
+	}
+	return false
+}
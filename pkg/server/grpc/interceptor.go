@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataXRequestID is the metadata key of request IDs, the gRPC equivalent of the
+// X-Request-ID header.
+const MetadataXRequestID = "x-request-id"
+
+// RequestID gets the request ID of a call from its context.
+func RequestID(ctx context.Context) string {
+	rid, _ := ctx.Value(requestid.ContextKey).(string)
+	return rid
+}
+
+// withRequestID reads the request ID from the incoming metadata, or generates one, then
+// sends it back in the response header & stores it in the context under the same key as
+// the HTTP middleware, so services & repositories find it the same way.
+func withRequestID(ctx context.Context) context.Context {
+	rid := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataXRequestID); len(values) > 0 {
+			rid = values[0]
+		}
+	}
+
+	if rid == "" {
+		rid = uuid.New().String()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataXRequestID, rid))
+
+	return context.WithValue(ctx, requestid.ContextKey, rid)
+}
+
+func unaryRequestID() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+func streamRequestID() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// recoverPanic turns a panic of a handler into an internal error, so it doesn't crash the
+// whole process.
+func recoverPanic(ctx context.Context, fullMethod string, err *error) {
+	if r := recover(); r != nil {
+		log.Stderr().Error().Timestamp().
+			Str("requestID", RequestID(ctx)).
+			Str("method", fullMethod).
+			Str(log.LogFieldError, fmt.Sprint(r)).
+			Str("stack", string(debug.Stack())).
+			Msg("Recovered from panic in gRPC handler")
+		*err = status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func unaryRecovery() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer recoverPanic(ctx, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+func streamRecovery() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) (err error) {
+		defer recoverPanic(ss.Context(), info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+// logCall logs a finished call like the HTTP logger middleware logs requests. Client
+// errors are logged as warnings & server errors as errors.
+func logCall(ctx context.Context, port, fullMethod string, start time.Time, err error) {
+	clientIP := "-"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+	}
+
+	userAgent := "-"
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	code := status.Code(err)
+	errMsg := "-"
+	if err != nil {
+		// Errors may carry a cause which isn't sent to clients, so the error itself is
+		// logged instead of its status message.
+		errMsg = err.Error()
+	}
+	msg := fmt.Sprintf("gRPC call to port %s", port)
+
+	with := func(logger *zerolog.Logger) zerolog.Logger {
+		return logger.With().
+			Str("requestID", RequestID(ctx)).
+			Str("code", code.String()).
+			Str("method", fullMethod).
+			Str("clientIP", clientIP).
+			Dur("latency", time.Since(start)).
+			Str("userAgent", userAgent).
+			Logger()
+	}
+
+	switch code {
+	case codes.OK:
+		dump := with(log.Stdout())
+		dump.Info().Timestamp().Msg(msg)
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal,
+		codes.Unavailable, codes.DataLoss:
+		dump := with(log.Stderr())
+		dump.Error().Timestamp().Str(log.LogFieldError, errMsg).Msg(msg)
+	default:
+		dump := with(log.Stdout())
+		dump.Warn().Timestamp().Str(log.LogFieldError, errMsg).Msg(msg)
+	}
+}
+
+func unaryLogger(port string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, port, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func streamLogger(port string) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), port, info.FullMethod, start, err)
+		return err
+	}
+}
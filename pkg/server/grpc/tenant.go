@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantConfig defines the config of the tenant interceptors. Header resolvers read the
+// metadata key of the same name, & subdomain resolvers read the :authority of calls. The
+// WithTenant func stores the tenant ID in the context of calls, which defaults to storing
+// it under the context key of the config.
+type TenantConfig struct {
+	tenant.Config
+}
+
+// metadataSource is the source of gRPC calls.
+type metadataSource struct {
+	md metadata.MD
+}
+
+func (s metadataSource) Header(name string) string {
+	if values := s.md.Get(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (s metadataSource) Host() string {
+	return s.Header(":authority")
+}
+
+// isServerMethod tells whether the method belongs to the services registered by the
+// server itself, which are called by infrastructure rather than by tenants.
+func isServerMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+// withTenant resolves the tenant of a call like the tenant HTTP middleware does, failing
+// with InvalidArgument or Unauthenticated codes instead of the equivalent HTTP statuses.
+func withTenant(ctx context.Context, config TenantConfig) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id, err := tenant.Resolve(config.Config, metadataSource{md})
+	switch err {
+	case nil:
+	case tenant.ErrMissingTenant, tenant.ErrInvalidTenant:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return config.WithTenant(ctx, id), nil
+}
+
+func (c *TenantConfig) defaults() {
+	if c.ContextKey == "" {
+		c.ContextKey = tenant.DefaultContextKey
+	}
+	if c.WithTenant == nil {
+		key := c.ContextKey
+		c.WithTenant = func(ctx context.Context, tenant string) context.Context {
+			return context.WithValue(ctx, key, tenant)
+		}
+	}
+}
+
+// UnaryTenant creates an interceptor storing the tenant of unary calls in their context.
+func UnaryTenant(config TenantConfig) grpc.UnaryServerInterceptor {
+	config.defaults()
+
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if isServerMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := withTenant(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamTenant creates an interceptor storing the tenant of streaming calls in their context.
+func StreamTenant(config TenantConfig) grpc.StreamServerInterceptor {
+	config.defaults()
+
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		if isServerMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := withTenant(ss.Context(), config)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -2,15 +2,23 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/health"
 	"github.com/satriajidam/go-gin-skeleton/pkg/log"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/logger"
 	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/middleware/requestid"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
 )
 
 var (
@@ -30,6 +38,12 @@ var (
 	}
 	CORSDefaultAllowCredentials = true
 	CORSDefaultMaxAge           = 12 * time.Hour
+
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+	DefaultMaxHeaderBytes    = http.DefaultMaxHeaderBytes
 )
 
 // Server represents the implementation of HTTP server object.
@@ -42,7 +56,36 @@ type Server struct {
 	routes       []route
 	enableCORS   bool
 	CORS         *cors.Config
+	corsHandler  atomic.Value
 	Port         string
+	// Listen is the listener spec of the server, see listener.ParseSpec. Defaults to the
+	// TCP port of the server.
+	Listen string
+	// ProxyProtocol reads the client addresses from PROXY protocol headers when set, so
+	// they're reported instead of the addresses of the proxies.
+	ProxyProtocol *listener.ProxyConfig
+	// TLS enables HTTPS when set.
+	TLS           *TLSConfig
+	stopTLSReload chan struct{}
+	// H2C serves HTTP/2 over cleartext connections alongside HTTP/1.1. It's ignored with
+	// TLS, where HTTP/2 is negotiated.
+	H2C bool
+	// HTTP3 serves HTTP/3 over QUIC alongside the TCP listener when set. It requires TLS.
+	HTTP3      *HTTP3Config
+	http3      http3Server
+	packetConn net.PacketConn
+	listener   listener.Managed
+	mu         sync.Mutex
+	draining   int32
+	// Timeouts & header size limit of the server connections, see net/http.Server.
+	// Zero timeouts mean no timeout.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// Health stores the dependency checkers reported by the readiness endpoint.
+	Health *health.Registry
 }
 
 type route struct {
@@ -68,23 +111,37 @@ func NewServer(port string, enableCORS bool, enablePredefinedRoutes bool) *Serve
 			requestid.New(),
 		},
 		loggerConfig: &logger.Config{
-			Stdout:   log.Stdout(),
-			Stderr:   log.Stderr(),
-			Routes:   []logger.Route{},
-			SkipPath: []string{},
+			Stdout:    log.Stdout(),
+			Stderr:    log.Stderr(),
+			Routes:    []logger.Route{},
+			SkipPath:  []string{},
+			SkipPaths: logger.NewSkipPaths(),
 		},
 		enableCORS: enableCORS,
 		CORS: &cors.Config{
 			AllowCredentials: CORSDefaultAllowCredentials,
 		},
-		routes: routes,
-		Port:   port,
+		routes:            routes,
+		Port:              port,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		ReadTimeout:       DefaultReadTimeout,
+		WriteTimeout:      DefaultWriteTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+		MaxHeaderBytes:    DefaultMaxHeaderBytes,
+		Health:            health.NewRegistry(health.DefaultCacheTTL, health.DefaultTimeout),
 	}
 
 	server.RouterGroup = RouterGroup{
 		server: server,
 	}
 
+	if enablePredefinedRoutes {
+		server.GET("/_/ready", false, server.readinessCheck)
+		// Kept for probes set up before /_/ready existed, which expect it to fail when the
+		// server can't serve requests.
+		server.GET("/_/health", false, server.readinessCheck)
+	}
+
 	return server
 }
 
@@ -97,6 +154,14 @@ func (s *Server) AddMiddleware(h gin.HandlerFunc) {
 // by the logger middleware.
 func (s *Server) LoggerSkipPaths(paths ...string) {
 	s.loggerConfig.SkipPath = append(s.loggerConfig.SkipPath, paths...)
+	s.loggerConfig.SkipPaths.Set(s.loggerConfig.SkipPath...)
+}
+
+// SetLoggerSkipPaths replaces the endpoint paths skipped by the logger middleware.
+// It can be called while the server is running.
+func (s *Server) SetLoggerSkipPaths(paths ...string) {
+	s.loggerConfig.SkipPath = paths
+	s.loggerConfig.SkipPaths.Set(paths...)
 }
 
 // GetRoutePaths retrieves all route paths registerd to this HTTP server.
@@ -108,6 +173,32 @@ func (s *Server) GetRoutePaths() []string {
 	return paths
 }
 
+// RouteInfo describes a route registered to the HTTP server.
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// GetRoutes retrieves all routes registered to this HTTP server, along with the names of
+// their last handlers like gin reports them.
+func (s *Server) GetRoutes() []RouteInfo {
+	routes := []RouteInfo{}
+	for _, r := range s.routes {
+		handler := ""
+		if len(r.handlers) > 0 {
+			h := r.handlers[len(r.handlers)-1]
+			handler = runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+		}
+		routes = append(routes, RouteInfo{
+			Method:  r.method,
+			Path:    r.relativePath,
+			Handler: handler,
+		})
+	}
+	return routes
+}
+
 func (s *Server) loadLoggerRoutes() {
 	for _, route := range s.routes {
 		s.loggerConfig.Routes = append(
@@ -142,48 +233,230 @@ func (s *Server) loadRoutes() {
 	}
 }
 
+func normalizeCORS(config *cors.Config) {
+	config.AllowAllOrigins = len(config.AllowOrigins) <= 0
+	if len(config.AllowMethods) <= 0 {
+		config.AllowMethods = CORSDefaultAllowMethods
+	}
+	if len(config.AllowHeaders) <= 0 {
+		config.AllowHeaders = CORSDefaultAllowHeaders
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = CORSDefaultMaxAge
+	}
+}
+
 func (s *Server) setupCORS() {
 	if s.enableCORS {
-		if len(s.CORS.AllowOrigins) <= 0 {
-			s.CORS.AllowAllOrigins = true
-		} else {
-			s.CORS.AllowAllOrigins = false
+		normalizeCORS(s.CORS)
+		s.corsHandler.Store(cors.New(*s.CORS))
+		s.AddMiddleware(func(ctx *gin.Context) {
+			s.corsHandler.Load().(gin.HandlerFunc)(ctx)
+		})
+	}
+}
+
+// UpdateCORS replaces the CORS config of the server. It can be called while the server is
+// running, the config is applied to the following requests.
+func (s *Server) UpdateCORS(config cors.Config) error {
+	normalizeCORS(&config)
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	*s.CORS = config
+	if s.enableCORS && s.corsHandler.Load() != nil {
+		s.corsHandler.Store(cors.New(config))
+	}
+
+	return nil
+}
+
+// Start starts the HTTP server.
+func (s *Server) Start() error {
+	if s.listener.Stopped() {
+		// Stopped before it's started.
+		return nil
+	}
+	srv := &http.Server{
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		ReadTimeout:       s.ReadTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
+		MaxHeaderBytes:    s.MaxHeaderBytes,
+	}
+
+	if s.TLS != nil {
+		tlsConfig, reloader, err := newTLSConfig(*s.TLS)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+		stopTLSReload := make(chan struct{})
+		s.mu.Lock()
+		s.stopTLSReload = stopTLSReload
+		s.mu.Unlock()
+		go reloader.watch(stopTLSReload)
+		if s.TLS.ClientCAFile != "" {
+			s.AddMiddleware(clientIdentity)
 		}
-		if len(s.CORS.AllowMethods) <= 0 {
-			s.CORS.AllowMethods = CORSDefaultAllowMethods
+	}
+
+	var h3 http3Server
+	h3Addr := ""
+	if s.HTTP3 != nil {
+		if s.TLS == nil {
+			return errors.New("HTTP/3 requires TLS")
 		}
-		if len(s.CORS.AllowHeaders) <= 0 {
-			s.CORS.AllowHeaders = CORSDefaultAllowHeaders
+		port := s.HTTP3.Port
+		if port == "" {
+			port = s.Port
 		}
-		if s.CORS.MaxAge <= 0 {
-			s.CORS.MaxAge = CORSDefaultMaxAge
+		var err error
+		h3Addr = fmt.Sprintf(":%s", port)
+		h3, err = newHTTP3Server(h3Addr, srv.TLSConfig, s.router, s.MaxHeaderBytes)
+		if err != nil {
+			return err
 		}
-		s.AddMiddleware(cors.New(*s.CORS))
+		s.AddMiddleware(newAltSvc(h3))
+		log.Info(fmt.Sprintf("Start HTTP/3 server on UDP port %s", port))
 	}
-}
 
-// Start starts the HTTP server.
-func (s *Server) Start() error {
-	log.Info(fmt.Sprintf("Start HTTP server on port %s", s.Port))
 	s.loadLoggerRoutes()
 	s.AddMiddleware(logger.New(s.Port, *s.loggerConfig))
 	s.setupCORS()
 	s.router.Use(s.middlewares...)
 	s.loadRoutes()
-	s.http = &http.Server{
-		Addr:    fmt.Sprintf(":%s", s.Port),
-		Handler: s.router,
+	srv.Handler = s.router
+	if s.H2C && s.TLS == nil {
+		srv.Handler = withH2C(s.router, s.IdleTimeout)
+	}
+	if s.ProxyProtocol != nil {
+		// The client address is given by the PROXY protocol header, so forwarding headers
+		// sent by clients aren't trusted.
+		s.router.ForwardedByClientIP = false
+	}
+
+	s.mu.Lock()
+	s.http, s.http3 = srv, h3
+	s.mu.Unlock()
+
+	// The UDP socket is created before the TCP listener, so the server only reports it
+	// listens once both are created.
+	var pc net.PacketConn
+	if h3 != nil {
+		var err error
+		if pc, err = listener.ListenPacket(h3Addr); err != nil {
+			_ = h3.Close()
+			return err
+		}
+		s.mu.Lock()
+		s.packetConn = pc
+		s.mu.Unlock()
 	}
-	if err := s.http.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+
+	l, err := s.listener.Listen(s.Listen, s.Port, s.ProxyProtocol)
+	if err != nil || l == nil {
+		if h3 != nil {
+			_ = h3.Close()
+			_ = pc.Close()
+		}
 		return err
 	}
-	return nil
+
+	if s.TLS != nil {
+		log.Info(fmt.Sprintf("Start HTTPS server on %s", listener.Addr(l)))
+	} else {
+		log.Info(fmt.Sprintf("Start HTTP server on %s", listener.Addr(l)))
+	}
+
+	if h3 == nil {
+		return s.serve(srv, l)
+	}
+
+	// Both listeners share the router. When one of them fails, the other one is closed.
+	errs := make(chan error, 2)
+	go func() { errs <- s.serve(srv, l) }()
+	go func() { errs <- h3.Serve(pc) }()
+
+	err = <-errs
+	if s.listener.Err(err) == nil || err == http.ErrServerClosed {
+		<-errs
+		return nil
+	}
+	_ = srv.Close()
+	_ = h3.Close()
+	<-errs
+	return err
+}
+
+// serve serves the listener, ignoring the errors caused by stopping the server or
+// releasing the listener.
+func (s *Server) serve(srv *http.Server, l net.Listener) error {
+	var err error
+	if s.TLS != nil {
+		// The certificate is served by the TLS config, so no files are given here.
+		err = srv.ServeTLS(l, "", "")
+	} else {
+		err = srv.Serve(l)
+	}
+	return s.listener.Err(err)
+}
+
+// Drain makes the readiness endpoint report the server as unready, so load balancers
+// stop sending it new requests before it's stopped.
+func (s *Server) Drain() {
+	atomic.StoreInt32(&s.draining, 1)
+}
+
+// Listening returns a channel which is closed once the server listens.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listener.Listening()
+}
+
+// Release stops accepting connections once the listener is handed off to another process,
+// while the accepted ones are still served until the server is stopped.
+func (s *Server) Release() error {
+	err := s.listener.Release()
+
+	// The new process reads the UDP socket from now on, so HTTP/3 connections can't be
+	// served anymore.
+	s.mu.Lock()
+	h3, pc := s.http3, s.packetConn
+	s.mu.Unlock()
+	if h3 != nil {
+		_ = h3.Close()
+	}
+	if pc != nil {
+		_ = pc.Close()
+	}
+
+	return err
 }
 
 // Stop stops the HTTP server.
 func (s *Server) Stop(ctx context.Context) error {
+	s.listener.Stop()
+	s.mu.Lock()
+	srv, h3, pc, stopTLSReload := s.http, s.http3, s.packetConn, s.stopTLSReload
+	s.stopTLSReload = nil
+	s.mu.Unlock()
+	if stopTLSReload != nil {
+		close(stopTLSReload)
+	}
+	if srv == nil {
+		return nil
+	}
 	log.Info(fmt.Sprintf("Stop HTTP server on port %s", s.Port))
-	if err := s.http.Shutdown(ctx); err != nil {
+	if h3 != nil {
+		// HTTP/3 connections can't be shut down gracefully yet.
+		_ = h3.Close()
+	}
+	if pc != nil {
+		// The UDP socket isn't closed by the HTTP/3 server.
+		_ = pc.Close()
+	}
+	if err := srv.Shutdown(ctx); err != nil {
 		return err
 	}
 	return nil
@@ -237,49 +510,63 @@ func (s *Server) HEAD(relativePath string, logPayload bool, handlers ...gin.Hand
 
 // RouterGroup groups path under one path prefix.
 type RouterGroup struct {
-	prefix string
-	server *Server
+	prefix   string
+	handlers []gin.HandlerFunc
+	server   *Server
 }
 
 // Group creates new RouterGroup with the given path prefix.
 func (rg *RouterGroup) Group(prefix string) *RouterGroup {
 	return &RouterGroup{
-		prefix: prefix,
-		server: rg.server,
+		prefix:   prefix,
+		handlers: rg.combineHandlers(),
+		server:   rg.server,
 	}
 }
 
+// Use adds handlers which run in front of the handlers of every route registered through
+// this group afterwards.
+func (rg *RouterGroup) Use(handlers ...gin.HandlerFunc) {
+	rg.handlers = append(rg.handlers, handlers...)
+}
+
+func (rg *RouterGroup) combineHandlers(handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+	combined := make([]gin.HandlerFunc, 0, len(rg.handlers)+len(handlers))
+	combined = append(combined, rg.handlers...)
+	return append(combined, handlers...)
+}
+
 // POST registers HTTP server endpoint with Post method.
 func (rg *RouterGroup) POST(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.POST(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.POST(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
 
 // GET registers HTTP server endpoint with Get method.
 func (rg *RouterGroup) GET(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.GET(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.GET(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
 
 // DELETE registers HTTP server endpoint with Delete method.
 func (rg *RouterGroup) DELETE(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.DELETE(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.DELETE(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
 
 // PATCH registers HTTP server endpoint with Patch method.
 func (rg *RouterGroup) PATCH(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.PATCH(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.PATCH(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
 
 // PUT registers HTTP server endpoint with Put method.
 func (rg *RouterGroup) PUT(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.PUT(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.PUT(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
 
 // OPTIONS registers HTTP server endpoint with Options method.
 func (rg *RouterGroup) OPTIONS(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.OPTIONS(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.OPTIONS(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
 
 // HEAD registers HTTP server endpoint with Head method.
 func (rg *RouterGroup) HEAD(relativePath string, logPayload bool, handlers ...gin.HandlerFunc) {
-	rg.server.HEAD(rg.prefix+relativePath, logPayload, handlers...)
+	rg.server.HEAD(rg.prefix+relativePath, logPayload, rg.combineHandlers(handlers...)...)
 }
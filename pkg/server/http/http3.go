@@ -0,0 +1,24 @@
+//go:build http3
+// +build http3
+
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/lucas-clemente/quic-go/http3"
+)
+
+func newHTTP3Server(
+	addr string, tlsConfig *tls.Config, handler http.Handler, maxHeaderBytes int,
+) (http3Server, error) {
+	return &http3.Server{
+		Server: &http.Server{
+			Addr:           addr,
+			Handler:        handler,
+			TLSConfig:      tlsConfig,
+			MaxHeaderBytes: maxHeaderBytes,
+		},
+	}, nil
+}
@@ -0,0 +1,16 @@
+//go:build !http3
+// +build !http3
+
+package http
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+)
+
+func newHTTP3Server(
+	addr string, tlsConfig *tls.Config, handler http.Handler, maxHeaderBytes int,
+) (http3Server, error) {
+	return nil, errors.New("HTTP/3 isn't supported by this build, build it with the http3 tag")
+}
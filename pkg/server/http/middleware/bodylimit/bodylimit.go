@@ -0,0 +1,73 @@
+// Package bodylimit rejects requests whose body exceeds the maximum size of their route.
+package bodylimit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/routelimit"
+)
+
+// DefaultLimit is the maximum request body size in bytes used when it isn't configured.
+var DefaultLimit int64 = 1 << 20
+
+// Config defines the config for body limit middleware.
+type Config struct {
+	// Limit is the maximum request body size in bytes. Negative means no limit.
+	Limit int64
+	// Routes overrides the limit of routes, keyed by method & relative path like
+	// "POST /v1/provider". See routelimit.Parse.
+	Routes map[string]int64
+}
+
+func abortTooLarge(ctx *gin.Context, limit int64) {
+	// The rest of the body isn't read, so the connection can't be reused.
+	ctx.Header("Connection", "close")
+	httpserver.AbortJSON(ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf(
+		"Request body exceeds the maximum size of %d bytes", limit,
+	))
+}
+
+// New initializes the body limit middleware. Requests with a larger Content-Length are
+// rejected right away, otherwise the body is read up to the limit, so handlers never
+// receive a truncated body.
+func New(config Config) gin.HandlerFunc {
+	if config.Limit == 0 {
+		config.Limit = DefaultLimit
+	}
+
+	return func(ctx *gin.Context) {
+		limit, ok := config.Routes[routelimit.Key(ctx.Request.Method, ctx.FullPath())]
+		if !ok {
+			limit = config.Limit
+		}
+
+		if limit < 0 || ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+			ctx.Next()
+			return
+		}
+
+		if ctx.Request.ContentLength > limit {
+			abortTooLarge(ctx, limit)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(ctx.Request.Body, limit+1))
+		if err != nil {
+			httpserver.AbortJSON(ctx, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if int64(len(body)) > limit {
+			abortTooLarge(ctx, limit)
+			return
+		}
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,237 @@
+// Package httpcache computes strong ETags for JSON responses, answers conditional GET
+// requests and optionally caches whole responses in Redis.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+)
+
+const (
+	// HeaderETag is the response header carrying the entity tag of the response body.
+	HeaderETag = "ETag"
+	// HeaderIfNoneMatch is the request header carrying the entity tags known by the client.
+	HeaderIfNoneMatch = "If-None-Match"
+	// HeaderCacheControl is the response header carrying the caching directives.
+	HeaderCacheControl = "Cache-Control"
+	// HeaderVary is the response header listing the request headers the response varies on.
+	HeaderVary = "Vary"
+	// HeaderXCache is the response header telling whether the response came from Redis.
+	HeaderXCache = "X-Cache"
+)
+
+// Config defines the config for HTTP cache middleware.
+type Config struct {
+	// Redis stores cached responses. When it's nil no response is cached server side.
+	Redis *redis.Connection
+	// Prefix is prepended to the Redis keys of the cached responses.
+	Prefix string
+	// ScopeKey is the gin context key of a value, like a tenant ID, which namespaces the
+	// cached responses so clients of different scopes never share them.
+	ScopeKey string
+}
+
+// Rule defines the caching behaviour of a route.
+type Rule struct {
+	// CacheControl is sent as the Cache-Control header of successful responses.
+	CacheControl string
+	// Vary lists the request headers which produce different responses.
+	Vary []string
+	// TTL enables caching whole responses in Redis for the given duration.
+	TTL time.Duration
+}
+
+// Cache creates HTTP cache handlers sharing the same Redis store.
+type Cache struct {
+	redis    *redis.Connection
+	prefix   string
+	scopeKey string
+}
+
+// entry stores a cached response.
+type entry struct {
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+}
+
+// bufferWriter holds back the response so its ETag can be computed before it's sent.
+type bufferWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferWriter) WriteHeaderNow() {}
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// New creates new HTTP cache.
+func New(config Config) *Cache {
+	if config.Prefix == "" {
+		config.Prefix = "httpcache"
+	}
+	return &Cache{
+		redis:    config.Redis,
+		prefix:   config.Prefix,
+		scopeKey: config.ScopeKey,
+	}
+}
+
+// ETag computes the strong entity tag of a response body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf("\"%s\"", base64.RawURLEncoding.EncodeToString(sum[:]))
+}
+
+// matchETag uses the weak comparison required for If-None-Match.
+func matchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
+
+// key hashes the scope, query & varying headers of the request, so their values can't
+// run into each other, under the path of the request.
+func (c *Cache) key(ctx *gin.Context, rule Rule) string {
+	h := sha256.New()
+	if c.scopeKey != "" {
+		h.Write([]byte(ctx.GetString(c.scopeKey)))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(ctx.Request.URL.Query().Encode()))
+	for _, name := range rule.Vary {
+		h.Write([]byte{0})
+		h.Write([]byte(ctx.GetHeader(name)))
+	}
+	return fmt.Sprintf("%s:%s:%s", c.prefix, ctx.Request.URL.Path, base64.RawURLEncoding.EncodeToString(h.Sum(nil)))
+}
+
+func writeEntry(ctx *gin.Context, rule Rule, e entry) {
+	header := ctx.Writer.Header()
+	header.Set(HeaderETag, e.ETag)
+	if rule.CacheControl != "" {
+		header.Set(HeaderCacheControl, rule.CacheControl)
+	}
+
+	if matchETag(ctx.GetHeader(HeaderIfNoneMatch), e.ETag) {
+		ctx.Writer.WriteHeader(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	header.Set("Content-Type", e.ContentType)
+	ctx.Writer.WriteHeader(e.Status)
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = ctx.Writer.Write(e.Body)
+}
+
+// Handler creates a gin handler which applies the given caching rule.
+// Register it in front of a GET route's handlers.
+func (c *Cache) Handler(rule Rule) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		if len(rule.Vary) > 0 {
+			ctx.Header(HeaderVary, strings.Join(rule.Vary, ", "))
+		}
+
+		useRedis := c.redis != nil && rule.TTL > 0
+
+		var key string
+		if useRedis {
+			key = c.key(ctx, rule)
+
+			var e entry
+			if err := c.redis.GetCache(ctx, key, &e); err == nil {
+				ctx.Header(HeaderXCache, "HIT")
+				writeEntry(ctx, rule, e)
+				ctx.Abort()
+				return
+			}
+			ctx.Header(HeaderXCache, "MISS")
+		}
+
+		original := ctx.Writer
+		writer := &bufferWriter{ResponseWriter: original, status: http.StatusOK}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		ctx.Writer = original
+
+		contentType := original.Header().Get("Content-Type")
+		if writer.status != http.StatusOK || !isJSON(contentType) {
+			original.WriteHeader(writer.status)
+			if writer.body.Len() > 0 {
+				_, _ = original.Write(writer.body.Bytes())
+			} else {
+				original.WriteHeaderNow()
+			}
+			return
+		}
+
+		e := entry{
+			Status:      writer.status,
+			ContentType: contentType,
+			ETag:        ETag(writer.body.Bytes()),
+			Body:        writer.body.Bytes(),
+		}
+
+		if useRedis {
+			_ = c.redis.SetCache(ctx, key, e, rule.TTL)
+		}
+
+		writeEntry(ctx, rule, e)
+	}
+}
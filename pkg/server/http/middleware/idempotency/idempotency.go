@@ -0,0 +1,238 @@
+// Package idempotency makes retried requests safe by replaying the stored response of
+// the first request sent with the same Idempotency-Key header.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the client generated idempotency key.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is the response header set on replayed responses.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// lockReleaseTimeout bounds releasing the lock of a key, which doesn't depend on the request.
+var lockReleaseTimeout = 2 * time.Second
+
+var (
+	// DefaultTTL is how long the response of the first request is kept for replays.
+	DefaultTTL = 24 * time.Hour
+	// DefaultLockTTL is how long a request holds its idempotency key while being processed.
+	DefaultLockTTL = 1 * time.Minute
+)
+
+// Config defines the config for idempotency middleware.
+type Config struct {
+	Redis *redis.Connection
+	// Prefix is prepended to the Redis keys of the stored responses.
+	Prefix string
+	// Methods which support idempotency keys. By default only POST requests do.
+	Methods []string
+	TTL     time.Duration
+	LockTTL time.Duration
+	// ScopeKey is the gin context key of a value, like a tenant ID, which namespaces the
+	// idempotency keys so clients of different scopes never share stored responses.
+	ScopeKey string
+}
+
+// record stores the first response sent for an idempotency key.
+type record struct {
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isStorable reports whether a response is final, so retries must get it replayed. These are
+// successful responses & client errors which the same request would fail with again.
+func isStorable(status int) bool {
+	switch {
+	case status >= http.StatusOK && status < http.StatusMultipleChoices:
+		return true
+	case status < http.StatusBadRequest || status >= http.StatusInternalServerError:
+		return false
+	}
+
+	switch status {
+	case http.StatusRequestTimeout,
+		http.StatusConflict,
+		http.StatusRequestEntityTooLarge,
+		http.StatusTooEarly,
+		http.StatusTooManyRequests:
+		// Timeouts, lock contention, size limits which may be raised & rate limits.
+		return false
+	default:
+		return true
+	}
+}
+
+func replay(ctx *gin.Context, rec *record) {
+	for name, values := range rec.Header {
+		// Keep headers already set for this request, like its own request ID.
+		if _, ok := ctx.Writer.Header()[name]; ok {
+			continue
+		}
+		for _, v := range values {
+			ctx.Writer.Header().Add(name, v)
+		}
+	}
+	ctx.Header(HeaderIdempotentReplayed, "true")
+	ctx.Status(rec.Status)
+	_, _ = ctx.Writer.Write(rec.Body)
+	ctx.Abort()
+}
+
+// New initializes the idempotency middleware. Requests without an Idempotency-Key header
+// are passed through untouched, and so are all requests when Redis isn't configured.
+func New(config Config) gin.HandlerFunc {
+	if config.Prefix == "" {
+		config.Prefix = "idempotency"
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost}
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultLockTTL
+	}
+
+	methods := make(map[string]struct{}, len(config.Methods))
+	for _, m := range config.Methods {
+		methods[m] = struct{}{}
+	}
+
+	return func(ctx *gin.Context) {
+		idemKey := ctx.GetHeader(HeaderIdempotencyKey)
+		if _, ok := methods[ctx.Request.Method]; !ok || idemKey == "" || config.Redis == nil {
+			ctx.Next()
+			return
+		}
+
+		if len(idemKey) > maxKeyLength {
+			httpserver.AbortJSON(ctx, http.StatusBadRequest, fmt.Sprintf("Invalid '%s' header", HeaderIdempotencyKey))
+			return
+		}
+
+		body, err := ioutil.ReadAll(ctx.Request.Body)
+		if err != nil {
+			httpserver.AbortJSON(ctx, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		fp := fingerprint(ctx.Request.Method, ctx.Request.URL.Path, body)
+		key := fmt.Sprintf("%s:%s", config.Prefix, idemKey)
+		if config.ScopeKey != "" {
+			if scope := ctx.GetString(config.ScopeKey); scope != "" {
+				key = fmt.Sprintf("%s:%s:%s", config.Prefix, scope, idemKey)
+			}
+		}
+
+		// lookup returns true when the request has been fully answered from the stored record.
+		lookup := func() bool {
+			var rec record
+			if err := config.Redis.GetCache(ctx, key, &rec); err != nil {
+				return false
+			}
+			if rec.Fingerprint != fp {
+				httpserver.AbortJSON(
+					ctx,
+					http.StatusUnprocessableEntity,
+					fmt.Sprintf("'%s' header was already used with a different payload", HeaderIdempotencyKey),
+				)
+				return true
+			}
+			replay(ctx, &rec)
+			return true
+		}
+
+		if lookup() {
+			return
+		}
+
+		lock, err := config.Redis.ObtainLock(ctx, fmt.Sprintf("%s:lock", key), config.LockTTL, 0)
+		if err != nil {
+			if redis.IsErrLockNotObtained(err) {
+				httpserver.AbortJSON(
+					ctx,
+					http.StatusConflict,
+					fmt.Sprintf("A request with the same '%s' header is still being processed", HeaderIdempotencyKey),
+				)
+				return
+			}
+			// Fail open: serving the request without idempotency beats rejecting it.
+			ctx.Next()
+			return
+		}
+		defer func() {
+			// Released even when the request context is cancelled, so retries aren't
+			// rejected until the lock expires.
+			releaseCtx, cancel := context.WithTimeout(context.Background(), lockReleaseTimeout)
+			defer cancel()
+			_ = lock.Release(releaseCtx)
+		}()
+
+		// The first request may have completed between the lookup & the lock.
+		if lookup() {
+			return
+		}
+
+		writer := &bodyWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		// Transient failures aren't stored, so retries with the same key can still succeed.
+		// The key is released by the deferred unlock either way.
+		if status := writer.Status(); isStorable(status) {
+			_ = config.Redis.SetCache(ctx, key, record{
+				Fingerprint: fp,
+				Status:      status,
+				Header:      writer.Header().Clone(),
+				Body:        writer.body.Bytes(),
+			}, config.TTL)
+		}
+	}
+}
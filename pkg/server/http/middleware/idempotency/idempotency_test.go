@@ -0,0 +1,238 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+)
+
+// testConnection connects to the Redis server at REDIS_TEST_ADDR with the optional
+// REDIS_TEST_PASSWORD, skipping the test when it isn't set. Keys are namespaced by the
+// test name, so tests don't share them.
+func testConnection(t *testing.T) *redis.Connection {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR isn't set")
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid REDIS_TEST_ADDR: %v", err)
+	}
+
+	conn, err := redis.NewConnection(context.Background(), redis.RedisConfig{
+		Host:      host,
+		Port:      port,
+		Password:  os.Getenv("REDIS_TEST_PASSWORD"),
+		Namespace: fmt.Sprintf("test:%s:%d", t.Name(), time.Now().UnixNano()),
+	})
+	if err != nil {
+		t.Fatalf("failed connecting to Redis: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.DeleteCacheByPrefix(context.Background(), "*")
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+// testRouter serves POST / with the middleware, answering with the given status & the
+// number of times the handler ran.
+func testRouter(config Config, status int, block <-chan struct{}) (*gin.Engine, *int32) {
+	gin.SetMode(gin.TestMode)
+
+	calls := new(int32)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("tenantID", ctx.GetHeader("X-Tenant-ID"))
+	})
+	router.Use(New(config))
+	router.POST("/", func(ctx *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		if block != nil {
+			<-block
+		}
+		ctx.JSON(status, map[string]int32{"call": n})
+	})
+
+	return router, calls
+}
+
+func send(router http.Handler, key, tenant, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	if tenant != "" {
+		req.Header.Set("X-Tenant-ID", tenant)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIsStorable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusCreated, true},
+		{http.StatusMovedPermanently, false},
+		{http.StatusBadRequest, true},
+		{http.StatusNotFound, true},
+		{http.StatusRequestTimeout, false},
+		{http.StatusConflict, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		if got := isStorable(tt.status); got != tt.want {
+			t.Errorf("%d: expected %v, got %v", tt.status, tt.want, got)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := fingerprint(http.MethodPost, "/a", []byte("{}"))
+
+	if fp != fingerprint(http.MethodPost, "/a", []byte("{}")) {
+		t.Fatal("expected same requests to have the same fingerprint")
+	}
+	if fp == fingerprint(http.MethodPost, "/a", []byte("{ }")) {
+		t.Fatal("expected different bodies to have different fingerprints")
+	}
+	if fp == fingerprint(http.MethodPost, "/a{}", nil) {
+		t.Fatal("expected path & body not to run into each other")
+	}
+}
+
+func TestWithoutRedis(t *testing.T) {
+	router, calls := testRouter(Config{}, http.StatusCreated, nil)
+
+	send(router, "key", "", `{}`)
+	w := send(router, "key", "", `{}`)
+
+	if w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatal("expected no replay without Redis")
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	router, calls := testRouter(Config{Redis: testConnection(t)}, http.StatusCreated, nil)
+
+	first := send(router, "key", "", `{"a":1}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected first request to succeed, got %d", first.Code)
+	}
+
+	second := send(router, "key", "", `{"a":1}`)
+	if second.Code != http.StatusCreated {
+		t.Fatalf("expected replayed status %d, got %d", http.StatusCreated, second.Code)
+	}
+	if second.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatal("expected replayed response to be flagged")
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("expected replayed body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", got)
+	}
+
+	// Requests without key & with other keys aren't replayed.
+	send(router, "", "", `{"a":1}`)
+	send(router, "other", "", `{"a":1}`)
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Fatalf("expected handler to run 3 times, ran %d times", got)
+	}
+}
+
+func TestReplayIsScoped(t *testing.T) {
+	router, calls := testRouter(
+		Config{Redis: testConnection(t), ScopeKey: "tenantID"}, http.StatusCreated, nil,
+	)
+
+	send(router, "key", "acme", `{}`)
+	if w := send(router, "key", "globex", `{}`); w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatal("expected responses not to be replayed to other scopes")
+	}
+	if w := send(router, "key", "acme", `{}`); w.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatal("expected response to be replayed to the same scope")
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", got)
+	}
+}
+
+func TestDifferentPayload(t *testing.T) {
+	router, calls := testRouter(Config{Redis: testConnection(t)}, http.StatusCreated, nil)
+
+	send(router, "key", "", `{"a":1}`)
+	w := send(router, "key", "", `{"a":2}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", got)
+	}
+}
+
+func TestConcurrentRequest(t *testing.T) {
+	block := make(chan struct{})
+	router, calls := testRouter(Config{Redis: testConnection(t)}, http.StatusCreated, block)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- send(router, "key", "", `{}`)
+	}()
+
+	// Wait until the first request holds the key.
+	for atomic.LoadInt32(calls) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if w := send(router, "key", "", `{}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected %d while the first request runs, got %d", http.StatusConflict, w.Code)
+	}
+
+	close(block)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("expected first request to succeed, got %d", w.Code)
+	}
+
+	if w := send(router, "key", "", `{}`); w.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatal("expected response to be replayed once the first request is done")
+	}
+}
+
+func TestTransientFailureIsNotStored(t *testing.T) {
+	router, calls := testRouter(Config{Redis: testConnection(t)}, http.StatusServiceUnavailable, nil)
+
+	send(router, "key", "", `{}`)
+	if w := send(router, "key", "", `{}`); w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatal("expected transient failures not to be replayed")
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", got)
+	}
+}
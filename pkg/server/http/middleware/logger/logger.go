@@ -23,6 +23,8 @@ type Config struct {
 	UTC      bool
 	Routes   []Route
 	SkipPath []string
+	// SkipPaths replaces SkipPath with route paths which can be changed while serving.
+	SkipPaths *SkipPaths
 }
 
 type Route struct {
@@ -66,12 +68,9 @@ func New(port string, config ...Config) gin.HandlerFunc {
 		newConfig = config[0]
 	}
 
-	var skipped map[string]struct{}
-	if length := len(newConfig.SkipPath); length > 0 {
-		skipped = make(map[string]struct{}, length)
-		for _, p := range newConfig.SkipPath {
-			skipped[p] = struct{}{}
-		}
+	skipped := newConfig.SkipPaths
+	if skipped == nil {
+		skipped = NewSkipPaths(newConfig.SkipPath...)
 	}
 
 	var logged map[string]bool
@@ -118,12 +117,7 @@ func New(port string, config ...Config) gin.HandlerFunc {
 
 		ctx.Next()
 
-		track := true
-		if _, ok := skipped[routePath]; ok {
-			track = false
-		}
-
-		if track {
+		if !skipped.Contains(routePath) {
 			end := time.Now()
 			latency := end.Sub(start)
 			if newConfig.UTC {
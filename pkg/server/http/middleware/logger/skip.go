@@ -0,0 +1,31 @@
+package logger
+
+import "sync/atomic"
+
+// SkipPaths is a set of route paths which aren't logged. It can be replaced while the
+// logger middleware is serving requests.
+type SkipPaths struct {
+	paths atomic.Value
+}
+
+// NewSkipPaths creates a set of skipped route paths.
+func NewSkipPaths(paths ...string) *SkipPaths {
+	s := &SkipPaths{}
+	s.Set(paths...)
+	return s
+}
+
+// Set replaces the skipped route paths.
+func (s *SkipPaths) Set(paths ...string) {
+	skipped := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		skipped[p] = struct{}{}
+	}
+	s.paths.Store(skipped)
+}
+
+// Contains tells whether the given route path is skipped.
+func (s *SkipPaths) Contains(path string) bool {
+	_, ok := s.paths.Load().(map[string]struct{})[path]
+	return ok
+}
@@ -0,0 +1,281 @@
+// Package ratelimit throttles HTTP requests using sliding window or token bucket algorithms.
+// Counters are kept in Redis so the limits are shared across replicas, with an in-memory
+// store used when Redis isn't configured or is failing.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+)
+
+// Algorithm represents a rate limiting algorithm.
+type Algorithm string
+
+const (
+	// SlidingWindow allows up to Limit requests within any Period long window.
+	SlidingWindow Algorithm = "sliding-window"
+	// TokenBucket allows bursts of up to Limit requests and refills the bucket in one Period.
+	TokenBucket Algorithm = "token-bucket"
+
+	// HeaderRateLimitLimit is the header for the request quota of the current window.
+	HeaderRateLimitLimit = "RateLimit-Limit"
+	// HeaderRateLimitRemaining is the header for the remaining quota of the current window.
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	// HeaderRateLimitReset is the header for the seconds left until the quota resets.
+	HeaderRateLimitReset = "RateLimit-Reset"
+	// HeaderRetryAfter is the header for the seconds to wait before retrying a rejected request.
+	HeaderRetryAfter = "Retry-After"
+	// HeaderXAPIKey is the default header used to identify clients by API key.
+	HeaderXAPIKey = "X-API-Key"
+)
+
+// KeyFunc extracts the key identifying the client being throttled from a request.
+type KeyFunc func(ctx *gin.Context) string
+
+// KeyByClientIP throttles requests per client IP.
+func KeyByClientIP(ctx *gin.Context) string {
+	return fmt.Sprintf("ip:%s", ctx.ClientIP())
+}
+
+// KeyByAPIKey throttles requests per API key found in the given header, falling back
+// to the client IP for requests without one.
+func KeyByAPIKey(header string) KeyFunc {
+	if header == "" {
+		header = HeaderXAPIKey
+	}
+	return func(ctx *gin.Context) string {
+		if key := ctx.GetHeader(header); key != "" {
+			return fmt.Sprintf("key:%s", key)
+		}
+		return KeyByClientIP(ctx)
+	}
+}
+
+// KeyByRoute throttles requests per route, regardless of the client.
+func KeyByRoute(ctx *gin.Context) string {
+	return "route"
+}
+
+// ParseKeyFunc returns the KeyFunc matching the given name: "ip", "apikey" or "route".
+func ParseKeyFunc(name string) (KeyFunc, error) {
+	switch strings.ToLower(name) {
+	case "", "ip":
+		return KeyByClientIP, nil
+	case "apikey":
+		return KeyByAPIKey(HeaderXAPIKey), nil
+	case "route":
+		return KeyByRoute, nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit key: %s", name)
+	}
+}
+
+// ParseAlgorithm returns the Algorithm matching the given name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch Algorithm(strings.ToLower(name)) {
+	case "", SlidingWindow:
+		return SlidingWindow, nil
+	case TokenBucket:
+		return TokenBucket, nil
+	default:
+		return "", fmt.Errorf("unsupported rate limit algorithm: %s", name)
+	}
+}
+
+// Rule defines a rate limit.
+type Rule struct {
+	// Name shares the counters between routes using rules with the same name.
+	// By default each route has its own counters.
+	Name      string
+	Algorithm Algorithm
+	Limit     int
+	Period    time.Duration
+	// KeyFunc defaults to KeyByClientIP.
+	KeyFunc KeyFunc
+}
+
+func (r *Rule) defaults() {
+	if r.Algorithm == "" {
+		r.Algorithm = SlidingWindow
+	}
+	if r.Limit < 1 {
+		r.Limit = 1
+	}
+	if r.Period <= 0 {
+		r.Period = time.Second
+	}
+	if r.KeyFunc == nil {
+		r.KeyFunc = KeyByClientIP
+	}
+}
+
+// DynamicRule is a rule which can be changed while its handlers serve requests, e.g. when
+// configurations are reloaded.
+type DynamicRule struct {
+	rule atomic.Value
+}
+
+// NewDynamicRule creates new dynamic rule.
+func NewDynamicRule(rule Rule) *DynamicRule {
+	r := &DynamicRule{}
+	r.Set(rule)
+	return r
+}
+
+// Set changes the rule, applied to the next requests.
+func (r *DynamicRule) Set(rule Rule) {
+	rule.defaults()
+	r.rule.Store(rule)
+}
+
+// Get returns the current rule.
+func (r *DynamicRule) Get() Rule {
+	return r.rule.Load().(Rule)
+}
+
+// Config defines the config for rate limit middleware.
+type Config struct {
+	// Redis stores the counters. When it's nil the counters are kept in memory.
+	Redis *redis.Connection
+	// Prefix is prepended to the Redis keys of the counters.
+	Prefix string
+}
+
+// fallbackWarnInterval is the minimum delay between the warnings logged while Redis keeps
+// failing, so a Redis outage doesn't log a warning for every request.
+var fallbackWarnInterval = time.Minute
+
+// Limiter creates rate limit handlers sharing the same counter stores.
+type Limiter struct {
+	prefix   string
+	redis    *redisStore
+	fallback *memoryStore
+
+	// degraded is set to 1 while the counters are kept in memory because Redis fails.
+	degraded int32
+	mu       sync.Mutex
+	lastWarn time.Time
+}
+
+// result stores the outcome of a rate limit check.
+type result struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// New creates new rate limiter.
+func New(config Config) *Limiter {
+	if config.Prefix == "" {
+		config.Prefix = "ratelimit"
+	}
+
+	limiter := &Limiter{
+		prefix:   config.Prefix,
+		fallback: newMemoryStore(),
+	}
+
+	if config.Redis != nil {
+		limiter.redis = &redisStore{conn: config.Redis}
+	}
+
+	return limiter
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+func (l *Limiter) allow(ctx *gin.Context, key string, rule Rule) result {
+	now := time.Now()
+
+	if l.redis != nil {
+		res, err := l.redis.allow(ctx, key, rule, now)
+		if err == nil {
+			if atomic.LoadInt32(&l.degraded) == 1 {
+				l.recovered()
+			}
+			return res
+		}
+		l.failed(err, now)
+	}
+
+	return l.fallback.allow(key, rule, now)
+}
+
+// failed logs the switch to the in-memory store, then a reminder at most once per
+// fallbackWarnInterval while Redis keeps failing.
+func (l *Limiter) failed(err error, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if atomic.CompareAndSwapInt32(&l.degraded, 0, 1) {
+		l.lastWarn = now
+		l.redis.conn.LogWarn(err, "Rate limiter falling back to in-memory store")
+		return
+	}
+
+	if now.Sub(l.lastWarn) >= fallbackWarnInterval {
+		l.lastWarn = now
+		l.redis.conn.LogWarn(err, "Rate limiter still using in-memory store")
+	}
+}
+
+// recovered logs the switch back to the Redis store.
+func (l *Limiter) recovered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if atomic.CompareAndSwapInt32(&l.degraded, 1, 0) {
+		l.redis.conn.LogInfo("Rate limiter back to Redis store")
+	}
+}
+
+// Handler creates a gin handler which throttles requests using the given rule.
+// Register it in front of a route's handlers to rate limit that route.
+func (l *Limiter) Handler(rule Rule) gin.HandlerFunc {
+	return l.DynamicHandler(NewDynamicRule(rule))
+}
+
+// DynamicHandler creates a gin handler like Handler, following the changes of the rule.
+func (l *Limiter) DynamicHandler(dynamic *DynamicRule) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rule := dynamic.Get()
+		scope := rule.Name
+		if scope == "" {
+			scope = fmt.Sprintf("%s:%s", ctx.Request.Method, ctx.FullPath())
+		}
+
+		// Algorithms store counters differently, so they don't share keys in case the rule
+		// changes.
+		key := fmt.Sprintf("%s:%s:%s:%s", l.prefix, rule.Algorithm, scope, rule.KeyFunc(ctx))
+		res := l.allow(ctx, key, rule)
+
+		ctx.Header(HeaderRateLimitLimit, strconv.Itoa(res.limit))
+		ctx.Header(HeaderRateLimitRemaining, strconv.Itoa(res.remaining))
+		ctx.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(res.reset)))
+
+		if !res.allowed {
+			ctx.Header(HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.retryAfter)))
+			httpserver.AbortJSON(ctx, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemorySlidingWindow(t *testing.T) {
+	s := newMemoryStore()
+	rule := NewDynamicRule(Rule{Algorithm: SlidingWindow, Limit: 2, Period: time.Second}).Get()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		at        time.Duration
+		allowed   bool
+		remaining int
+		reset     time.Duration
+	}{
+		{"first", 0, true, 1, time.Second},
+		{"second", 200 * time.Millisecond, true, 0, 800 * time.Millisecond},
+		{"over limit", 400 * time.Millisecond, false, 0, 600 * time.Millisecond},
+		{"first expired", 1000 * time.Millisecond, true, 0, 200 * time.Millisecond},
+		{"window full again", 1100 * time.Millisecond, false, 0, 100 * time.Millisecond},
+		{"all expired", 3 * time.Second, true, 1, time.Second},
+	}
+
+	for _, tt := range tests {
+		res := s.allow("key", rule, now.Add(tt.at))
+		if res.allowed != tt.allowed {
+			t.Errorf("%s: expected allowed %v, got %v", tt.name, tt.allowed, res.allowed)
+		}
+		if res.remaining != tt.remaining {
+			t.Errorf("%s: expected remaining %d, got %d", tt.name, tt.remaining, res.remaining)
+		}
+		if res.reset != tt.reset {
+			t.Errorf("%s: expected reset %v, got %v", tt.name, tt.reset, res.reset)
+		}
+		if res.limit != rule.Limit {
+			t.Errorf("%s: expected limit %d, got %d", tt.name, rule.Limit, res.limit)
+		}
+	}
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	s := newMemoryStore()
+	rule := NewDynamicRule(Rule{Algorithm: TokenBucket, Limit: 2, Period: time.Second}).Get()
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{"burst", 0, true, 1, 0},
+		{"burst", 0, true, 0, 0},
+		{"empty bucket", 0, false, 0, 500 * time.Millisecond},
+		{"half refilled", 250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+		{"one token refilled", 500 * time.Millisecond, true, 0, 0},
+		{"full again", 3 * time.Second, true, 1, 0},
+	}
+
+	for _, tt := range tests {
+		res := s.allow("key", rule, now.Add(tt.at))
+		if res.allowed != tt.allowed {
+			t.Errorf("%s: expected allowed %v, got %v", tt.name, tt.allowed, res.allowed)
+		}
+		if res.remaining != tt.remaining {
+			t.Errorf("%s: expected remaining %d, got %d", tt.name, tt.remaining, res.remaining)
+		}
+		if res.retryAfter != tt.retryAfter {
+			t.Errorf("%s: expected retry after %v, got %v", tt.name, tt.retryAfter, res.retryAfter)
+		}
+	}
+}
+
+func TestMemoryKeysAreIndependent(t *testing.T) {
+	s := newMemoryStore()
+	rule := NewDynamicRule(Rule{Limit: 1, Period: time.Second}).Get()
+	now := time.Now()
+
+	if res := s.allow("a", rule, now); !res.allowed {
+		t.Fatal("expected first request of a to be allowed")
+	}
+	if res := s.allow("a", rule, now); res.allowed {
+		t.Fatal("expected second request of a to be throttled")
+	}
+	if res := s.allow("b", rule, now); !res.allowed {
+		t.Fatal("expected first request of b to be allowed")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := New(Config{})
+	rule := NewDynamicRule(Rule{Limit: 1, Period: time.Minute, KeyFunc: KeyByAPIKey("")})
+
+	router := gin.New()
+	router.GET("/", limiter.DynamicHandler(rule), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	request := func(apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderXAPIKey, apiKey)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("a")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected first request to succeed, got %d", w.Code)
+	}
+	if got := w.Header().Get(HeaderRateLimitLimit); got != "1" {
+		t.Errorf("expected %s 1, got %q", HeaderRateLimitLimit, got)
+	}
+	if got := w.Header().Get(HeaderRateLimitRemaining); got != "0" {
+		t.Errorf("expected %s 0, got %q", HeaderRateLimitRemaining, got)
+	}
+
+	w = request("a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second request to be throttled, got %d", w.Code)
+	}
+	if got := w.Header().Get(HeaderRetryAfter); got != "60" {
+		t.Errorf("expected %s 60, got %q", HeaderRetryAfter, got)
+	}
+
+	if w = request("b"); w.Code != http.StatusOK {
+		t.Fatalf("expected request of another client to succeed, got %d", w.Code)
+	}
+
+	// Raising the limit applies to the next requests without recreating the handler.
+	rule.Set(Rule{Limit: 2, Period: time.Minute, KeyFunc: KeyByAPIKey("")})
+	if w = request("a"); w.Code != http.StatusOK {
+		t.Fatalf("expected request under the raised limit to succeed, got %d", w.Code)
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Algorithm
+		wantErr bool
+	}{
+		{"", SlidingWindow, false},
+		{"sliding-window", SlidingWindow, false},
+		{"Token-Bucket", TokenBucket, false},
+		{"leaky-bucket", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAlgorithm(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+)
+
+var (
+	// Sliding window log: every accepted request is a member of a sorted set scored by
+	// its timestamp, so the window is counted exactly at any point in time.
+	slidingWindowScript = redisv8.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", KEYS[1], window)
+local reset = window
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+	// Token bucket: the bucket state is refilled lazily based on the time elapsed since
+	// the last request.
+	tokenBucketScript = redisv8.NewScript(`
+local now = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local rate = capacity / period
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], period)
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) / rate), retry}
+`)
+)
+
+type redisStore struct {
+	conn *redis.Connection
+}
+
+func newMember(now time.Time) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%d-%s", now.UnixNano(), hex.EncodeToString(b))
+}
+
+func toInt64s(res interface{}) ([]int64, error) {
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, redis.ErrFailedCommand
+	}
+
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		n, ok := v.(int64)
+		if !ok {
+			return nil, redis.ErrFailedCommand
+		}
+		ints[i] = n
+	}
+
+	return ints, nil
+}
+
+func (s *redisStore) allow(
+	ctx context.Context, key string, rule Rule, now time.Time,
+) (result, error) {
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+	periodMs := rule.Period.Milliseconds()
+
+	switch rule.Algorithm {
+	case TokenBucket:
+		res, err := s.conn.RunScript(
+			ctx, tokenBucketScript, []string{key}, nowMs, rule.Limit, periodMs,
+		).Result()
+		if err != nil {
+			return result{}, err
+		}
+		v, err := toInt64s(res)
+		if err != nil || len(v) != 4 {
+			return result{}, redis.ErrFailedCommand
+		}
+		return result{
+			allowed:    v[0] == 1,
+			limit:      rule.Limit,
+			remaining:  int(v[1]),
+			reset:      time.Duration(v[2]) * time.Millisecond,
+			retryAfter: time.Duration(v[3]) * time.Millisecond,
+		}, nil
+	default:
+		res, err := s.conn.RunScript(
+			ctx, slidingWindowScript, []string{key}, nowMs, periodMs, rule.Limit, newMember(now),
+		).Result()
+		if err != nil {
+			return result{}, err
+		}
+		v, err := toInt64s(res)
+		if err != nil || len(v) != 3 {
+			return result{}, redis.ErrFailedCommand
+		}
+		return result{
+			allowed:    v[0] == 1,
+			limit:      rule.Limit,
+			remaining:  int(v[1]),
+			reset:      time.Duration(v[2]) * time.Millisecond,
+			retryAfter: time.Duration(v[2]) * time.Millisecond,
+		}, nil
+	}
+}
+
+// memoryStore keeps the counters in the process memory. The limits aren't shared across
+// replicas, so it's only meant as a fallback when Redis isn't available.
+type memoryStore struct {
+	mu        sync.Mutex
+	windows   map[string][]time.Time
+	buckets   map[string]*bucket
+	expiry    map[string]time.Time
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		windows:   map[string][]time.Time{},
+		buckets:   map[string]*bucket{},
+		expiry:    map[string]time.Time{},
+		lastSweep: time.Now(),
+	}
+}
+
+// sweep removes idle counters so the store doesn't grow with every client ever seen.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, exp := range s.expiry {
+		if now.After(exp) {
+			delete(s.windows, key)
+			delete(s.buckets, key)
+			delete(s.expiry, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (s *memoryStore) allow(key string, rule Rule, now time.Time) result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	s.expiry[key] = now.Add(rule.Period)
+
+	if rule.Algorithm == TokenBucket {
+		return s.allowTokenBucket(key, rule, now)
+	}
+
+	return s.allowSlidingWindow(key, rule, now)
+}
+
+func (s *memoryStore) allowSlidingWindow(key string, rule Rule, now time.Time) result {
+	start := now.Add(-rule.Period)
+
+	window := s.windows[key]
+	i := 0
+	for i < len(window) && !window[i].After(start) {
+		i++
+	}
+	window = window[i:]
+
+	allowed := len(window) < rule.Limit
+	if allowed {
+		window = append(window, now)
+	}
+	s.windows[key] = window
+
+	reset := rule.Period
+	if len(window) > 0 {
+		reset = window[0].Add(rule.Period).Sub(now)
+	}
+
+	return result{
+		allowed:    allowed,
+		limit:      rule.Limit,
+		remaining:  rule.Limit - len(window),
+		reset:      reset,
+		retryAfter: reset,
+	}
+}
+
+func (s *memoryStore) allowTokenBucket(key string, rule Rule, now time.Time) result {
+	capacity := float64(rule.Limit)
+	rate := capacity / float64(rule.Period)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, ts: now}
+		s.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.ts); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)*rate)
+	}
+	b.ts = now
+
+	res := result{limit: rule.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.allowed = true
+	} else {
+		res.retryAfter = time.Duration(math.Ceil((1 - b.tokens) / rate))
+	}
+
+	res.remaining = int(math.Floor(b.tokens))
+	res.reset = time.Duration(math.Ceil((capacity - b.tokens) / rate))
+
+	return res
+}
@@ -7,6 +7,10 @@ import (
 
 const HeaderXRequestID = "X-Request-ID"
 
+// ContextKey is the key of the request ID stored in the gin context, which makes it
+// available through the context passed down to services & repositories.
+const ContextKey = "requestID"
+
 // New initializes the request ID middleware.
 func New() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -20,6 +24,7 @@ func New() gin.HandlerFunc {
 
 		// Attach the request ID to the response writer.
 		ctx.Header(HeaderXRequestID, rid)
+		ctx.Set(ContextKey, rid)
 
 		ctx.Next()
 	}
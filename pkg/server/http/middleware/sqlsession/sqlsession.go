@@ -0,0 +1,16 @@
+// Package sqlsession attaches a SQL session to each request, so reads following a write
+// in the same request are served by the primary database instead of a lagging replica.
+package sqlsession
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+)
+
+// New initializes the SQL session middleware.
+func New() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(sql.SessionKey, sql.NewSession())
+		ctx.Next()
+	}
+}
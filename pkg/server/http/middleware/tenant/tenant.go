@@ -0,0 +1,254 @@
+// Package tenant resolves the tenant a request belongs to and stores its ID in the
+// gin context, so services & repositories can isolate the data of each tenant.
+package tenant
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+)
+
+const (
+	// HeaderXTenantID is the default request header carrying the tenant ID.
+	HeaderXTenantID = "X-Tenant-ID"
+	// DefaultContextKey is the default key of the tenant ID stored in the gin context.
+	DefaultContextKey = "tenantID"
+	// DefaultClaim is the default JWT claim carrying the tenant ID.
+	DefaultClaim = "tenant"
+)
+
+var (
+	// ErrInvalidToken occurs when the bearer token is malformed, expired or badly signed.
+	ErrInvalidToken = errors.New("Invalid bearer token")
+	// ErrMissingTenant occurs when a tenant is required but the request doesn't carry any.
+	ErrMissingTenant = errors.New("Missing tenant")
+	// ErrInvalidTenant occurs when the tenant ID isn't made of 1 to 64 letters, digits,
+	// underscores or dashes.
+	ErrInvalidTenant = errors.New("Invalid tenant")
+
+	tenantIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+// Source gives resolvers access to the request carrying the tenant ID, which is either an
+// HTTP request or a gRPC call.
+type Source interface {
+	// Header returns the value of a header, or of a metadata key for gRPC calls.
+	Header(name string) string
+	// Host returns the host the request is sent to.
+	Host() string
+}
+
+// ginSource is the source of HTTP requests.
+type ginSource struct {
+	ctx *gin.Context
+}
+
+func (s ginSource) Header(name string) string {
+	return s.ctx.GetHeader(name)
+}
+
+func (s ginSource) Host() string {
+	return s.ctx.Request.Host
+}
+
+// Resolver extracts the tenant ID of a request. It returns an empty ID when the request
+// doesn't carry any, and an error when it carries an invalid one.
+type Resolver func(src Source) (string, error)
+
+// FromHeader resolves the tenant ID from a request header.
+func FromHeader(header string) Resolver {
+	if header == "" {
+		header = HeaderXTenantID
+	}
+	return func(src Source) (string, error) {
+		return src.Header(header), nil
+	}
+}
+
+// FromSubdomain resolves the tenant ID from the subdomain of the given base domain
+// found in the request host, e.g. "acme" in "acme.api.example.com".
+func FromSubdomain(baseDomain string) Resolver {
+	suffix := fmt.Sprintf(".%s", strings.TrimPrefix(strings.ToLower(baseDomain), "."))
+	return func(src Source) (string, error) {
+		host := strings.ToLower(src.Host())
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !strings.HasSuffix(host, suffix) {
+			return "", nil
+		}
+		return strings.TrimSuffix(host, suffix), nil
+	}
+}
+
+// FromJWTClaim resolves the tenant ID from a claim of the HS256 JWT bearer token found
+// in the Authorization header. The token must be signed with the given secret.
+func FromJWTClaim(claim string, secret []byte) Resolver {
+	if claim == "" {
+		claim = DefaultClaim
+	}
+	return func(src Source) (string, error) {
+		auth := src.Header("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return "", nil
+		}
+
+		claims, err := verifyJWT(strings.TrimPrefix(auth, "Bearer "), secret)
+		if err != nil {
+			return "", err
+		}
+
+		tenant, _ := claims[claim].(string)
+		return tenant, nil
+	}
+}
+
+// verifyJWT checks the signature & expiry of an HS256 JWT and returns its claims.
+func verifyJWT(token string, secret []byte) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || len(secret) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(fmt.Sprintf("%s.%s", parts[0], parts[1])))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidToken
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() >= int64(exp) {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// ParseResolver creates a resolver by name: header, jwt or subdomain. The argument is
+// the header name, the JWT claim or the base domain respectively.
+func ParseResolver(name, arg string, jwtSecret []byte) (Resolver, error) {
+	switch strings.ToLower(name) {
+	case "header":
+		return FromHeader(arg), nil
+	case "jwt":
+		if len(jwtSecret) == 0 {
+			return nil, errors.New("tenant jwt resolver requires a secret")
+		}
+		return FromJWTClaim(arg, jwtSecret), nil
+	case "subdomain":
+		if arg == "" {
+			return nil, errors.New("tenant subdomain resolver requires a base domain")
+		}
+		return FromSubdomain(arg), nil
+	default:
+		return nil, fmt.Errorf("unsupported tenant resolver: %s", name)
+	}
+}
+
+// Config defines the config for tenant middleware.
+type Config struct {
+	// Resolvers are tried in order until one finds a tenant ID.
+	Resolvers []Resolver
+	// Required rejects requests without tenant ID. Otherwise they get the default tenant.
+	Required      bool
+	DefaultTenant string
+	// ContextKey is the gin context key the tenant ID is stored under, for middlewares
+	// scoping their data by tenant.
+	ContextKey string
+	// WithTenant also stores the tenant ID in the context of the request, for services
+	// reading it through their own context key.
+	WithTenant func(ctx context.Context, tenant string) context.Context
+}
+
+// Resolve resolves the tenant ID of a request using the config. It fails with
+// ErrMissingTenant or ErrInvalidTenant, or with the error of a resolver when the request
+// carries an invalid one.
+func Resolve(config Config, src Source) (string, error) {
+	tenant := ""
+
+	for _, resolve := range config.Resolvers {
+		id, err := resolve(src)
+		if err != nil {
+			return "", err
+		}
+		if id != "" {
+			tenant = id
+			break
+		}
+	}
+
+	if tenant == "" {
+		if config.Required {
+			return "", ErrMissingTenant
+		}
+		tenant = config.DefaultTenant
+	}
+
+	if tenant != "" && !tenantIDPattern.MatchString(tenant) {
+		return "", ErrInvalidTenant
+	}
+
+	return tenant, nil
+}
+
+// New initializes the tenant middleware.
+func New(config Config) gin.HandlerFunc {
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultContextKey
+	}
+
+	return func(ctx *gin.Context) {
+		tenant, err := Resolve(config, ginSource{ctx})
+		switch err {
+		case nil:
+		case ErrMissingTenant, ErrInvalidTenant:
+			httpserver.AbortJSON(ctx, http.StatusBadRequest, err.Error())
+			return
+		default:
+			httpserver.AbortJSON(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		ctx.Set(config.ContextKey, tenant)
+		if config.WithTenant != nil {
+			ctx.Request = ctx.Request.WithContext(config.WithTenant(ctx.Request.Context(), tenant))
+		}
+		ctx.Next()
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"math/rand"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/version"
 )
 
 // List of predefined routes.
@@ -16,9 +18,15 @@ import (
 var predefinedRoutes = []route{
 	{
 		method:       http.MethodGet,
-		relativePath: "/_/health",
+		relativePath: "/_/live",
 		logPayload:   false,
-		handlers:     []gin.HandlerFunc{healthCheck},
+		handlers:     []gin.HandlerFunc{livenessCheck},
+	},
+	{
+		method:       http.MethodGet,
+		relativePath: "/_/version",
+		logPayload:   false,
+		handlers:     []gin.HandlerFunc{versionInfo},
 	},
 	{
 		method:       http.MethodGet,
@@ -116,11 +124,41 @@ func getStatusCodeAndText(code int) (int, string) {
 	return http.StatusBadRequest, http.StatusText(http.StatusBadRequest)
 }
 
-// healthCheck is a simple endpoint for checking HTTP server health.
-func healthCheck(ctx *gin.Context) {
+// livenessCheck tells the HTTP server is running, regardless of its dependencies.
+func livenessCheck(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, map[string]string{"status": "healthy"})
 }
 
+// versionInfo reports the build information of the running binary.
+func versionInfo(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, version.Get())
+}
+
+// readinessCheck reports the status of the dependencies registered to the server's
+// health registry, and fails when any critical one is down.
+func (s *Server) readinessCheck(ctx *gin.Context) {
+	if atomic.LoadInt32(&s.draining) == 1 {
+		ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"status": "draining",
+		})
+		return
+	}
+
+	report := s.Health.Check(ctx)
+
+	status := "ready"
+	statusCode := http.StatusOK
+	if !report.Ready {
+		status = "unready"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(statusCode, map[string]interface{}{
+		"status": status,
+		"checks": report.Checks,
+	})
+}
+
 // simulateStatusCode simulates response based on the given status code.
 func simulateStatusCode(ctx *gin.Context) {
 	code, err := strconv.Atoi(ctx.Param("code"))
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTP3Config stores HTTP/3 configurations of the HTTP server.
+type HTTP3Config struct {
+	// Port is the UDP port of the HTTP/3 listener. Defaults to the port of the server.
+	Port string
+}
+
+// http3Server is an HTTP/3 server listening for QUIC connections. HTTP/3 is only
+// available in binaries built with the http3 tag, as QUIC support depends on the
+// Go version.
+type http3Server interface {
+	// Serve serves the UDP socket, which isn't closed by Close.
+	Serve(conn net.PacketConn) error
+	Close() error
+	// SetQuicHeaders adds the Alt-Svc header advertising the HTTP/3 listener.
+	SetQuicHeaders(hdr http.Header) error
+}
+
+// withH2C serves HTTP/2 over cleartext connections, either by prior knowledge or by
+// upgrading HTTP/1.1 connections, alongside HTTP/1.1.
+func withH2C(h http.Handler, idleTimeout time.Duration) http.Handler {
+	return h2c.NewHandler(h, &http2.Server{IdleTimeout: idleTimeout})
+}
+
+// newAltSvc advertises the HTTP/3 listener to clients, so they may switch to it for
+// the following requests.
+func newAltSvc(h3 http3Server) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		_ = h3.SetQuicHeaders(ctx.Writer.Header())
+		ctx.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// response mirrors the JSON response format of the HTTP handlers.
+type response struct {
+	Status  string      `json:"status"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+// AbortJSON aborts the request with a failed response in the format of the HTTP handlers,
+// so requests rejected by middlewares look the same to clients.
+func AbortJSON(ctx *gin.Context, code int, msg string) {
+	ctx.AbortWithStatusJSON(code, response{
+		Status:  "failed",
+		Code:    code,
+		Message: msg,
+		Data:    nil,
+	})
+}
@@ -0,0 +1,40 @@
+// Package routelimit parses limits configured per HTTP route. It only depends on the
+// standard library, so configurations can be validated without importing the server.
+package routelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses route limits formatted as "METHOD /relative/path=limit", e.g.
+// "POST /v1/provider=4096", keyed by Key.
+func Parse(routes []string) (map[string]int64, error) {
+	limits := map[string]int64{}
+	for _, r := range routes {
+		i := strings.LastIndex(r, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid route limit %q, must be METHOD /path=limit", r)
+		}
+
+		fields := strings.Fields(r[:i])
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid route limit %q, must be METHOD /path=limit", r)
+		}
+
+		limit, err := strconv.ParseInt(strings.TrimSpace(r[i+1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route limit %q, limit must be an integer", r)
+		}
+
+		limits[Key(fields[0], fields[1])] = limit
+	}
+	return limits, nil
+}
+
+// Key is the key of a route limit, made of the method & the relative path of the route
+// like gin reports it, e.g. "POST /v1/provider".
+func Key(method, path string) string {
+	return fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/http/tlsutil"
+	"github.com/satriajidam/go-gin-skeleton/pkg/util"
+)
+
+// ClientIdentityKey is the key of the verified client certificate identity stored in the
+// gin context when mutual TLS is enabled.
+const ClientIdentityKey = "clientIdentity"
+
+// Client certificate verification modes.
+const (
+	ClientAuthRequire  = tlsutil.ClientAuthRequire
+	ClientAuthOptional = tlsutil.ClientAuthOptional
+)
+
+// DefaultTLSMinVersion is the minimum TLS version used when it isn't configured.
+const DefaultTLSMinVersion = tlsutil.DefaultMinVersion
+
+// TLSConfig stores HTTPS configurations of the HTTP server.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version, one of 1.0, 1.1, 1.2 or 1.3.
+	MinVersion string
+	// CipherSuites are the names of the cipher suites enabled for TLS 1.0 to 1.2, e.g.
+	// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Empty means Go's defaults. TLS 1.3 cipher
+	// suites aren't configurable.
+	CipherSuites []string
+	// ClientCAFile is the CA bundle verifying client certificates. Setting it enables
+	// mutual TLS.
+	ClientCAFile string
+	// ClientAuth is either ClientAuthRequire or ClientAuthOptional, which only verifies
+	// client certificates when they're given.
+	ClientAuth string
+	// ReloadInterval is how often the certificate & CA files are checked for rotation.
+	// Zero disables reloading.
+	ReloadInterval time.Duration
+}
+
+// certReloader keeps the server certificate & the client CAs loaded from files, and loads
+// them again when the files are rotated.
+type certReloader struct {
+	config TLSConfig
+	base   *tls.Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamp     string
+}
+
+// newTLSConfig creates the TLS config of the server, whose certificate & client CAs are
+// read from the reloader.
+func newTLSConfig(cfg TLSConfig) (*tls.Config, *certReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, nil, errors.New("TLS requires a certificate file & a key file")
+	}
+
+	minVersion, err := tlsutil.ParseVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cipherSuites, err := tlsutil.ParseCipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := &certReloader{config: cfg}
+	if err := r.load(); err != nil {
+		return nil, nil, err
+	}
+
+	r.base = &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: r.getCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	if cfg.ClientCAFile != "" {
+		clientAuth, err := tlsutil.ParseClientAuth(cfg.ClientAuth)
+		if err != nil {
+			return nil, nil, err
+		}
+		r.base.ClientAuth = clientAuth
+
+		// The client CAs are read on each handshake, so rotated CA bundles are applied too.
+		tlsConfig := r.base.Clone()
+		tlsConfig.GetConfigForClient = r.getConfigForClient
+		return tlsConfig, r, nil
+	}
+
+	return r.base, r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+// load reads the certificate & the client CAs. The current ones are kept on failure.
+func (r *certReloader) load() error {
+	stamp := util.FileStamp(r.files()...)
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed loading TLS certificate: %v", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed loading client CA file: %v", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file %s has no PEM certificates", r.config.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.stamp = &cert, clientCAs, stamp
+	r.mu.Unlock()
+
+	return nil
+}
+
+// watch reloads the files whenever they change until stop is closed.
+func (r *certReloader) watch(stop <-chan struct{}) {
+	if r.config.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		last := r.stamp
+		r.mu.RUnlock()
+
+		if util.FileStamp(r.files()...) == last {
+			continue
+		}
+
+		if err := r.load(); err != nil {
+			// Files may be rotated one by one, so the pair is retried on the next check.
+			log.Error(err, "Failed reloading TLS certificates, keeping the current ones")
+			continue
+		}
+
+		log.Info("TLS certificates reloaded")
+	}
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	config := r.base.Clone()
+	config.ClientCAs = r.clientCAs
+	return config, nil
+}
+
+// ClientIdentity describes the verified certificate of a mutual TLS client.
+type ClientIdentity struct {
+	CommonName     string   `json:"commonName"`
+	Organization   []string `json:"organization,omitempty"`
+	DNSNames       []string `json:"dnsNames,omitempty"`
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	SerialNumber   string   `json:"serialNumber"`
+	// Fingerprint is the SHA-256 hash of the certificate in hex.
+	Fingerprint string `json:"fingerprint"`
+}
+
+func newClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	id := &ClientIdentity{
+		CommonName:     cert.Subject.CommonName,
+		Organization:   cert.Subject.Organization,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		SerialNumber:   cert.SerialNumber.String(),
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+
+	return id
+}
+
+// clientIdentity stores the identity of verified client certificates in the gin context.
+func clientIdentity(ctx *gin.Context) {
+	if ctx.Request.TLS != nil && len(ctx.Request.TLS.VerifiedChains) > 0 &&
+		len(ctx.Request.TLS.VerifiedChains[0]) > 0 {
+		ctx.Set(ClientIdentityKey, newClientIdentity(ctx.Request.TLS.VerifiedChains[0][0]))
+	}
+	ctx.Next()
+}
+
+// GetClientIdentity gets the verified client certificate identity of the request. It's
+// only available when mutual TLS is enabled & the client sent a certificate.
+func GetClientIdentity(ctx *gin.Context) (*ClientIdentity, bool) {
+	v, ok := ctx.Get(ClientIdentityKey)
+	if !ok {
+		return nil, false
+	}
+	id, ok := v.(*ClientIdentity)
+	return id, ok
+}
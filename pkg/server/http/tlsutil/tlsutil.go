@@ -0,0 +1,73 @@
+// Package tlsutil parses TLS configurations of the HTTP server. It only depends on the
+// standard library, so configurations can be validated without importing the server.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// Client certificate verification modes.
+const (
+	ClientAuthRequire  = "require"
+	ClientAuthOptional = "optional"
+)
+
+// DefaultMinVersion is the minimum TLS version used when it isn't configured.
+const DefaultMinVersion = "1.2"
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseVersion converts a TLS version name like 1.2 to its crypto/tls value.
+func ParseVersion(name string) (uint16, error) {
+	if name == "" {
+		name = DefaultMinVersion
+	}
+	version, ok := versions[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version: %s", name)
+	}
+	return version, nil
+}
+
+// ParseCipherSuites converts cipher suite names to their crypto/tls IDs. Insecure cipher
+// suites aren't supported.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	ids := map[string]uint16{}
+	for _, cs := range tls.CipherSuites() {
+		ids[cs.Name] = cs.ID
+	}
+
+	suites := []uint16{}
+	for _, name := range names {
+		id, ok := ids[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cipher suite: %s", name)
+		}
+		suites = append(suites, id)
+	}
+
+	return suites, nil
+}
+
+// ParseClientAuth converts a client certificate verification mode to its crypto/tls value.
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case "", ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unsupported client auth mode: %s", mode)
+	}
+}
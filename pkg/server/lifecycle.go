@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+)
+
+// DefaultStopTimeout is how long each component may take to stop by default.
+var DefaultStopTimeout = 5 * time.Second
+
+// Hook is a named component of the application, like a database connection or a server.
+// Hooks are started in the order they're appended & stopped in the reverse order.
+type Hook struct {
+	Name string
+	// OnStart starts the component. It must return once the component is started.
+	OnStart func(ctx context.Context) error
+	// OnStop stops the component within the deadline of the given context.
+	OnStop func(ctx context.Context) error
+	// StopTimeout overrides the stop timeout of the lifecycle for this component.
+	StopTimeout time.Duration
+}
+
+// Drainer is implemented by servers which stop reporting themselves as ready before
+// they're stopped, so load balancers stop sending them new requests.
+type Drainer interface {
+	Drain()
+}
+
+// Listening is implemented by servers which report once they listen, so they're only
+// considered started, and the process ready for an upgrade, once they accept connections.
+type Listening interface {
+	Listening() <-chan struct{}
+}
+
+// Releaser is implemented by servers which stop accepting connections while serving the
+// accepted ones, once their listeners are handed off to another process on upgrade.
+type Releaser interface {
+	Release() error
+}
+
+// LifecycleConfig stores configurations of the lifecycle.
+type LifecycleConfig struct {
+	// PreStopDelay is how long the lifecycle waits between draining the servers & stopping
+	// the components, which gives load balancers time to notice the servers are unready.
+	PreStopDelay time.Duration
+	// StopTimeout is how long each component may take to stop.
+	StopTimeout time.Duration
+	// StopTimeouts overrides the stop timeouts of components by name, see stoptimeout.Parse.
+	StopTimeouts map[string]time.Duration
+	// UpgradeTimeout is how long a new process started on SIGUSR2 may take to start, see
+	// Upgrade.
+	UpgradeTimeout time.Duration
+}
+
+// Lifecycle starts the components of the application in order, waits until the
+// application is terminated or a server fails, then stops them in the reverse order.
+type Lifecycle struct {
+	config    LifecycleConfig
+	hooks     []Hook
+	started   int
+	drainers  []Drainer
+	releasers []Releaser
+	errs      chan error
+	wg        sync.WaitGroup
+}
+
+// NewLifecycle creates a new lifecycle.
+func NewLifecycle(config LifecycleConfig) *Lifecycle {
+	if config.StopTimeout <= 0 {
+		config.StopTimeout = DefaultStopTimeout
+	}
+
+	return &Lifecycle{
+		config: config,
+		errs:   make(chan error, 1),
+	}
+}
+
+// Append registers hooks. Hooks appended after Start are started by the next Start or Run.
+func (l *Lifecycle) Append(hooks ...Hook) {
+	l.hooks = append(l.hooks, hooks...)
+}
+
+// AppendServer registers a server as a hook. The server runs in the background once it's
+// started, and its failure shuts down the application. Servers implementing Listening are
+// started once they listen, and their failures before that fail the start instead.
+func (l *Lifecycle) AppendServer(name string, s Server) {
+	if d, ok := s.(Drainer); ok {
+		l.drainers = append(l.drainers, d)
+	}
+	if r, ok := s.(Releaser); ok {
+		l.releasers = append(l.releasers, r)
+	}
+
+	l.Append(Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			done := make(chan error, 1)
+			l.wg.Add(1)
+			go func() {
+				defer l.wg.Done()
+				done <- s.Start()
+			}()
+
+			if listening, ok := s.(Listening); ok {
+				select {
+				case <-listening.Listening():
+				case err := <-done:
+					// The server failed or is stopped before it listens.
+					return err
+				}
+			}
+
+			l.wg.Add(1)
+			go func() {
+				defer l.wg.Done()
+				if err := <-done; err != nil {
+					select {
+					case l.errs <- fmt.Errorf("%s failed: %v", name, err):
+					default:
+					}
+				}
+			}()
+
+			return nil
+		},
+		OnStop: s.Stop,
+	})
+}
+
+// Start starts the hooks which aren't started yet. When a hook fails, the started ones are
+// stopped & the error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for ; l.started < len(l.hooks); l.started++ {
+		h := l.hooks[l.started]
+		if h.OnStart == nil {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Starting %s", h.Name))
+		if err := h.OnStart(ctx); err != nil {
+			err = fmt.Errorf("failed starting %s: %v", h.Name, err)
+			if stopErr := l.stop(false); stopErr != nil {
+				return fmt.Errorf("%v; %v", err, stopErr)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop drains the servers, waits for the pre-stop delay, then stops the started hooks in
+// the reverse order, each within its own timeout. All stop errors are returned.
+func (l *Lifecycle) Stop() error {
+	return l.stop(true)
+}
+
+func (l *Lifecycle) stop(drain bool) error {
+	if drain {
+		for _, d := range l.drainers {
+			d.Drain()
+		}
+
+		if l.config.PreStopDelay > 0 && len(l.drainers) > 0 {
+			log.Info(fmt.Sprintf("Draining, waiting %s before stopping", l.config.PreStopDelay))
+			time.Sleep(l.config.PreStopDelay)
+		}
+	}
+
+	errs := []string{}
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+
+		timeout := h.StopTimeout
+		if t, ok := l.config.StopTimeouts[h.Name]; ok {
+			timeout = t
+		}
+		if timeout <= 0 {
+			timeout = l.config.StopTimeout
+		}
+
+		log.Info(fmt.Sprintf("Stopping %s", h.Name))
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := h.OnStop(ctx)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed stopping %s: %v", h.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Run starts the hooks, waits until the context is done or a server fails, then stops
+// them. It returns the server failure along with the stop errors, if any.
+//
+// On SIGUSR2 the current binary is started again with the listeners of the servers. Once
+// the new process is started, the current one is stopped, so it's upgraded without
+// dropping connections. The current process keeps running when the upgrade fails.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	upgrade := make(chan os.Signal, 1)
+	signal.Notify(upgrade, syscall.SIGUSR2)
+	defer signal.Stop(upgrade)
+
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+
+	if err := NotifyUpgradeReady(); err != nil {
+		log.Error(err, "Failed notifying the previous process of the upgrade")
+	}
+
+	runErr := l.wait(ctx, upgrade)
+
+	stopErr := l.Stop()
+	l.wg.Wait()
+
+	switch {
+	case runErr != nil && stopErr != nil:
+		return fmt.Errorf("%v; %v", runErr, stopErr)
+	case runErr != nil:
+		return runErr
+	case stopErr != nil:
+		return stopErr
+	}
+
+	log.Info("All components stopped properly")
+
+	return nil
+}
+
+// wait blocks until the context is done, a server fails or the process is upgraded. It
+// returns the server failure.
+func (l *Lifecycle) wait(ctx context.Context, upgrade <-chan os.Signal) error {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Shutting down")
+			return nil
+		case err := <-l.errs:
+			log.Error(err, "Shutting down")
+			return err
+		case <-upgrade:
+			log.Info("Received SIGUSR2, upgrading")
+			if err := Upgrade(l.config.UpgradeTimeout); err != nil {
+				log.Error(err, "Failed upgrading, keeping the current process")
+				continue
+			}
+			log.Info("Upgraded, shutting down the previous process")
+			// The new process accepts the connections from now on. The ones accepted here
+			// are served until the servers are stopped.
+			for _, r := range l.releasers {
+				if err := r.Release(); err != nil {
+					log.Error(err, "Failed releasing listener")
+				}
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package listener
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	activeMu sync.Mutex
+	// active stores the sockets created by Listen & ListenPacket by their specs.
+	active = map[string]io.Closer{}
+)
+
+func setActive(spec string, socket io.Closer) {
+	activeMu.Lock()
+	active[spec] = socket
+	activeMu.Unlock()
+}
+
+// handoffName is the LISTEN_FDNAMES name of a socket handed off to a child process.
+// Names can't contain colons, so the spec is escaped.
+func handoffName(spec string) string {
+	return url.QueryEscape(spec)
+}
+
+// Handoff stores the sockets passed to a child process, which gets them back from Listen &
+// ListenPacket with the same specs.
+type Handoff struct {
+	// Files are the duplicated file descriptors of the sockets, passed as extra files
+	// starting from file descriptor 3.
+	Files []*os.File
+	// Env sets LISTEN_FDS, LISTEN_FDNAMES & LISTEN_PARENT_PID for the child process.
+	Env []string
+}
+
+// Close closes the duplicated file descriptors once they're passed to the child process.
+func (h *Handoff) Close() {
+	for _, f := range h.Files {
+		f.Close()
+	}
+}
+
+// NewHandoff duplicates the file descriptors of the sockets created by Listen &
+// ListenPacket, so a child process started with them can serve on the same sockets.
+func NewHandoff() (*Handoff, error) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	specs := make([]string, 0, len(active))
+	for spec := range active {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	h := &Handoff{}
+	names := []string{}
+	for _, spec := range specs {
+		f, err := socketFile(active[spec])
+		if err != nil {
+			h.Close()
+			return nil, fmt.Errorf("failed handing off listener %s: %v", spec, err)
+		}
+		h.Files = append(h.Files, f)
+		names = append(names, handoffName(spec))
+	}
+
+	h.Env = []string{
+		fmt.Sprintf("%s=%d", EnvListenFDs, len(h.Files)),
+		fmt.Sprintf("%s=%s", EnvListenFDNames, strings.Join(names, ":")),
+		fmt.Sprintf("%s=%s", EnvListenParentPID, strconv.Itoa(os.Getpid())),
+	}
+
+	return h, nil
+}
+
+func socketFile(socket io.Closer) (*os.File, error) {
+	switch l := socket.(type) {
+	case *net.TCPListener:
+		return l.File()
+	case *net.UnixListener:
+		// The socket file is used by the child process, so it's kept when the listener of
+		// this process is closed.
+		l.SetUnlinkOnClose(false)
+		return l.File()
+	case *net.UDPConn:
+		return l.File()
+	default:
+		return nil, fmt.Errorf("unsupported listener type %T", l)
+	}
+}
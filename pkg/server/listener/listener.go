@@ -0,0 +1,275 @@
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Networks of listener specs.
+const (
+	NetworkTCP  = "tcp"
+	NetworkUnix = "unix"
+	NetworkFD   = "fd"
+	// NetworkUDP is the network of packet sockets, see ListenPacket. It isn't accepted by
+	// listener specs.
+	NetworkUDP = "udp"
+)
+
+// Environment variables of systemd socket activation, see sd_listen_fds(3).
+const (
+	EnvListenFDs     = "LISTEN_FDS"
+	EnvListenPID     = "LISTEN_PID"
+	EnvListenFDNames = "LISTEN_FDNAMES"
+	// EnvListenParentPID replaces LISTEN_PID for listeners handed off by a parent process,
+	// which can't know the process ID of its child in advance, see Files.
+	EnvListenParentPID = "LISTEN_PARENT_PID"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd.
+const listenFDsStart = 3
+
+// Spec describes where a server listens.
+type Spec struct {
+	// Network is one of NetworkTCP, NetworkUnix or NetworkFD.
+	Network string
+	// Address is host:port for tcp, the socket path for unix, and the file descriptor
+	// number or name for fd.
+	Address string
+}
+
+// ParseSpec parses a listener spec, one of:
+//
+//	tcp://host:port, tcp://:port or host:port
+//	unix:///path/to/socket
+//	fd://3 or fd://name, a listener inherited through LISTEN_FDS by number or by its
+//	name in LISTEN_FDNAMES
+func ParseSpec(spec string) (Spec, error) {
+	network, address := NetworkTCP, spec
+	if i := strings.Index(spec, "://"); i >= 0 {
+		network, address = strings.ToLower(spec[:i]), spec[i+3:]
+	}
+
+	if address == "" {
+		return Spec{}, fmt.Errorf("listener spec %q has no address", spec)
+	}
+
+	switch network {
+	case NetworkTCP:
+		if _, port, err := net.SplitHostPort(address); err != nil || port == "" {
+			return Spec{}, fmt.Errorf("invalid tcp listener address %q, must be host:port", address)
+		}
+	case NetworkUnix:
+	case NetworkFD:
+		if fd, err := strconv.Atoi(address); err == nil && fd < listenFDsStart {
+			return Spec{}, fmt.Errorf(
+				"invalid inherited file descriptor %d, must be at least %d", fd, listenFDsStart,
+			)
+		}
+	default:
+		return Spec{}, fmt.Errorf("unsupported listener network: %s", network)
+	}
+
+	return Spec{Network: network, Address: address}, nil
+}
+
+// String formats the spec as a URL.
+func (s Spec) String() string {
+	return fmt.Sprintf("%s://%s", s.Network, s.Address)
+}
+
+// Listen creates the listener of a spec, see ParseSpec.
+func Listen(spec string) (net.Listener, error) {
+	s, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listeners handed off by the parent process are named after their specs.
+	l, err := inheritedListener(handoffName(s.String()))
+	if err != nil {
+		l, err = listen(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setActive(s.String(), l)
+
+	return l, nil
+}
+
+// ListenPacket creates a UDP socket on the address, host:port or :port. Like the listeners
+// created by Listen, it's handed off to child processes on upgrade.
+func ListenPacket(address string) (net.PacketConn, error) {
+	s := Spec{Network: NetworkUDP, Address: address}
+
+	c, err := inheritedPacketConn(handoffName(s.String()))
+	if err != nil {
+		c, err = net.ListenPacket(NetworkUDP, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setActive(s.String(), c)
+
+	return c, nil
+}
+
+func listen(s Spec) (net.Listener, error) {
+	switch s.Network {
+	case NetworkUnix:
+		if err := removeStaleSocket(s.Address); err != nil {
+			return nil, err
+		}
+		return net.Listen(NetworkUnix, s.Address)
+	case NetworkFD:
+		return inheritedListener(s.Address)
+	default:
+		return net.Listen(NetworkTCP, s.Address)
+	}
+}
+
+// removeStaleSocket removes the socket file left by a previous process, so the path can be
+// listened on again. Sockets which still accept connections are kept.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+
+	if conn, err := net.Dial(NetworkUnix, path); err == nil {
+		conn.Close()
+		return fmt.Errorf("unix socket %s is in use", path)
+	}
+
+	return os.Remove(path)
+}
+
+type inherited struct {
+	name string
+	file *os.File
+	used bool
+}
+
+var (
+	inheritedOnce sync.Once
+	inheritedMu   sync.Mutex
+	inheritedFDs  map[int]*inherited
+)
+
+// loadInherited reads the file descriptors passed through LISTEN_FDS once. The variables
+// are only honored when LISTEN_PID is the current process, or when LISTEN_PARENT_PID is
+// its parent. They're unset afterwards, so they aren't passed on to child processes.
+func loadInherited() {
+	inheritedFDs = map[int]*inherited{}
+
+	pid, parentPID := os.Getenv(EnvListenPID), os.Getenv(EnvListenParentPID)
+	fds, names := os.Getenv(EnvListenFDs), os.Getenv(EnvListenFDNames)
+	for _, env := range []string{EnvListenPID, EnvListenParentPID, EnvListenFDs, EnvListenFDNames} {
+		os.Unsetenv(env)
+	}
+
+	if pid != strconv.Itoa(os.Getpid()) && (parentPID == "" || parentPID != strconv.Itoa(os.Getppid())) {
+		return
+	}
+
+	count, err := strconv.Atoi(fds)
+	if err != nil || count < 1 {
+		return
+	}
+
+	fdNames := strings.Split(names, ":")
+	for i := 0; i < count; i++ {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := strconv.Itoa(fd)
+		if i < len(fdNames) && fdNames[i] != "" {
+			name = fdNames[i]
+		}
+		inheritedFDs[fd] = &inherited{
+			name: name,
+			file: os.NewFile(uintptr(fd), name),
+		}
+	}
+}
+
+// takeInherited returns the inherited file descriptor with the given number or name. Each
+// file descriptor is only taken once.
+func takeInherited(address string) (*inherited, error) {
+	inheritedOnce.Do(loadInherited)
+
+	inheritedMu.Lock()
+	defer inheritedMu.Unlock()
+
+	var found *inherited
+	if fd, err := strconv.Atoi(address); err == nil {
+		found = inheritedFDs[fd]
+	} else {
+		for _, in := range inheritedFDs {
+			if in.name == address {
+				found = in
+				break
+			}
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no listener %q is inherited through %s", address, EnvListenFDs)
+	}
+	if found.used {
+		return nil, fmt.Errorf("inherited listener %q is already in use", address)
+	}
+	found.used = true
+
+	return found, nil
+}
+
+// inheritedListener returns the inherited listener with the given file descriptor number
+// or name.
+func inheritedListener(address string) (net.Listener, error) {
+	in, err := takeInherited(address)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := net.FileListener(in.file)
+	if err != nil {
+		return nil, fmt.Errorf("inherited file descriptor %q isn't a listening socket: %v", address, err)
+	}
+	// The listener owns a duplicate of the file descriptor.
+	in.file.Close()
+
+	return l, nil
+}
+
+// inheritedPacketConn returns the inherited UDP socket with the given file descriptor name.
+func inheritedPacketConn(address string) (net.PacketConn, error) {
+	in, err := takeInherited(address)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := net.FilePacketConn(in.file)
+	if err != nil {
+		return nil, fmt.Errorf("inherited file descriptor %q isn't a packet socket: %v", address, err)
+	}
+	// The socket owns a duplicate of the file descriptor.
+	in.file.Close()
+
+	return c, nil
+}
+
+// Addr describes the address of a listener for logs, e.g. tcp://[::]:80.
+func Addr(l net.Listener) string {
+	if l == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s", l.Addr().Network(), l.Addr().String())
+}
@@ -0,0 +1,92 @@
+package listener
+
+import (
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Spec
+		wantErr bool
+	}{
+		{":8080", Spec{NetworkTCP, ":8080"}, false},
+		{"127.0.0.1:8080", Spec{NetworkTCP, "127.0.0.1:8080"}, false},
+		{"[::1]:8080", Spec{NetworkTCP, "[::1]:8080"}, false},
+		{"tcp://:8080", Spec{NetworkTCP, ":8080"}, false},
+		{"TCP://:8080", Spec{NetworkTCP, ":8080"}, false},
+		{"unix:///run/app.sock", Spec{NetworkUnix, "/run/app.sock"}, false},
+		{"fd://3", Spec{NetworkFD, "3"}, false},
+		{"fd://http", Spec{NetworkFD, "http"}, false},
+		{"", Spec{}, true},
+		{"tcp://", Spec{}, true},
+		{"8080", Spec{}, true},
+		{"tcp://localhost", Spec{}, true},
+		{"tcp://localhost:", Spec{}, true},
+		{"unix://", Spec{}, true},
+		{"fd://2", Spec{}, true},
+		{"udp://:8080", Spec{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSpec(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.spec, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v", tt.spec, tt.want, got)
+		}
+	}
+}
+
+func TestSpecString(t *testing.T) {
+	for _, spec := range []string{"tcp://:8080", "unix:///run/app.sock", "fd://3"} {
+		s, err := ParseSpec(spec)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", spec, err)
+		}
+		if s.String() != spec {
+			t.Errorf("expected %q, got %q", spec, s.String())
+		}
+	}
+}
+
+func TestManagedStoppedBeforeListen(t *testing.T) {
+	var m Managed
+	m.Stop()
+
+	l, err := m.Listen("127.0.0.1:0", "", nil)
+	if err != nil || l != nil {
+		t.Fatalf("expected no listener once stopped, got %v, %v", l, err)
+	}
+
+	select {
+	case <-m.Listening():
+		t.Fatal("expected listening channel to stay open")
+	default:
+	}
+}
+
+func TestManagedListen(t *testing.T) {
+	var m Managed
+
+	l, err := m.Listen("127.0.0.1:0", "", nil)
+	if err != nil {
+		t.Fatalf("failed listening: %v", err)
+	}
+	defer l.Close()
+
+	select {
+	case <-m.Listening():
+	default:
+		t.Fatal("expected listening channel to be closed")
+	}
+
+	if err := m.Release(); err != nil {
+		t.Fatalf("failed releasing listener: %v", err)
+	}
+	if _, err := l.Accept(); m.Err(err) != nil {
+		t.Fatalf("expected error of the released listener to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package listener
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Managed manages the listener of a server across its lifecycle. The listener isn't
+// created once the server is stopped, which may happen before it's started, and it's
+// closed when it's released on upgrade. Errors of serving it caused by either are ignored.
+type Managed struct {
+	mu        sync.Mutex
+	listener  net.Listener
+	listening chan struct{}
+	stopped   bool
+	released  bool
+}
+
+// Listen creates the listener of the spec, defaulting to the TCP port, which reads PROXY
+// protocol headers when the proxy config is set. It returns no listener when the server
+// is already stopped.
+func (m *Managed) Listen(spec, port string, proxy *ProxyConfig) (net.Listener, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return nil, nil
+	}
+
+	if spec == "" {
+		spec = fmt.Sprintf(":%s", port)
+	}
+
+	l, err := Listen(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy != nil {
+		l = NewProxyListener(l, *proxy)
+	}
+
+	m.listener = l
+	close(m.listeningChan())
+
+	return l, nil
+}
+
+// Listening returns a channel which is closed once the listener is created.
+func (m *Managed) Listening() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listeningChan()
+}
+
+func (m *Managed) listeningChan() chan struct{} {
+	if m.listening == nil {
+		m.listening = make(chan struct{})
+	}
+	return m.listening
+}
+
+// Release stops accepting connections once the listener is handed off to another process,
+// while the accepted ones are still served until the server is stopped.
+func (m *Managed) Release() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.listener == nil || m.released {
+		return nil
+	}
+	m.released = true
+
+	return m.listener.Close()
+}
+
+// Stop marks the server as stopped, so the listener isn't created anymore.
+func (m *Managed) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+}
+
+// Stopped tells whether the server is stopped.
+func (m *Managed) Stopped() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopped
+}
+
+// Err returns the error of serving the listener, unless the server is stopped or the
+// listener is released, which make serving fail as expected.
+func (m *Managed) Err(err error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped || m.released {
+		return nil
+	}
+
+	return err
+}
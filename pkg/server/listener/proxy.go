@@ -0,0 +1,302 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProxyHeaderTimeout is how long a connection may take to send its PROXY protocol
+// header by default.
+var DefaultProxyHeaderTimeout = 5 * time.Second
+
+// ErrNoProxyHeader is returned when a connection which must send a PROXY protocol header
+// doesn't start with one.
+var ErrNoProxyHeader = errors.New("connection doesn't start with a PROXY protocol header")
+
+// proxyV2Signature starts PROXY protocol v2 headers.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyV1MaxLength is the maximum length of PROXY protocol v1 headers, including CRLF.
+const proxyV1MaxLength = 107
+
+// ProxyConfig stores PROXY protocol configurations of a listener.
+type ProxyConfig struct {
+	// TrustedNetworks are the networks of the proxies in front of the listener. Connections
+	// from them must start with a PROXY protocol header, other connections are served as
+	// they are. Empty means every connection must start with a header.
+	TrustedNetworks []*net.IPNet
+	// HeaderTimeout is how long a connection may take to send its header.
+	HeaderTimeout time.Duration
+}
+
+// ParseNetworks parses CIDRs like 10.0.0.0/8. Single IPs are parsed as networks of
+// one address.
+func ParseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid network %q, must be a CIDR or an IP", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q, must be a CIDR or an IP", cidr)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// NewProxyListener wraps a listener so the remote & local addresses of its connections are
+// read from their PROXY protocol v1 or v2 headers, which load balancers like HAProxy &
+// AWS NLB send ahead of the client data.
+func NewProxyListener(l net.Listener, config ProxyConfig) net.Listener {
+	if config.HeaderTimeout <= 0 {
+		config.HeaderTimeout = DefaultProxyHeaderTimeout
+	}
+	return &proxyListener{Listener: l, config: config}
+}
+
+type proxyListener struct {
+	net.Listener
+	config ProxyConfig
+}
+
+// Accept waits for the next connection. The header isn't read here, so slow clients don't
+// block the accept loop. It's read on the first use of the connection instead.
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.trusted(conn.RemoteAddr()) {
+		return conn, nil
+	}
+
+	return &proxyConn{
+		Conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: l.config.HeaderTimeout,
+	}, nil
+}
+
+// trusted reports whether the connection comes from a proxy. Unix socket peers are local,
+// so they're always trusted.
+func (l *proxyListener) trusted(addr net.Addr) bool {
+	if len(l.config.TrustedNetworks) == 0 {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return true
+	}
+
+	for _, network := range l.config.TrustedNetworks {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// proxyConn is a connection whose addresses are given by its PROXY protocol header.
+type proxyConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	remote net.Addr
+	local  net.Addr
+	err    error
+}
+
+// readHeader reads the header once, before anything else is read from the connection.
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		c.remote, c.local, c.err = readProxyHeader(c.reader)
+		_ = c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address given by the header, or the address of the proxy
+// when the header has none, like health checks of the proxy itself.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address given by the header, or the address of the
+// listener when the header has none.
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readProxyHeader reads a PROXY protocol v1 or v2 header. The addresses are nil when the
+// header doesn't carry them.
+func readProxyHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch b[0] {
+	case 'P':
+		return readProxyV1(r)
+	case proxyV2Signature[0]:
+		return readProxyV2(r)
+	default:
+		return nil, nil, ErrNoProxyHeader
+	}
+}
+
+// readProxyV1 reads a header like "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n".
+func readProxyV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil && err != bufio.ErrBufferFull {
+		return nil, nil, err
+	}
+	if len(line) > proxyV1MaxLength || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("invalid PROXY protocol v1 header, it isn't terminated by CRLF")
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if fields[0] != "PROXY" || len(fields) < 2 {
+		return nil, nil, ErrNoProxyHeader
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, nil, fmt.Errorf("unsupported PROXY protocol v1 protocol: %s", fields[1])
+	}
+
+	if len(fields) != 6 {
+		return nil, nil, errors.New("invalid PROXY protocol v1 header, it must have 6 fields")
+	}
+
+	remote, err := parseProxyV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	local, err := parseProxyV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return remote, local, nil
+}
+
+func parseProxyV1Addr(host, port string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 address: %s", host)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 port: %s", port)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+// readProxyV2 reads a binary header, see https://www.haproxy.org/download/2.0/doc/proxy-protocol.txt.
+// TLVs are skipped.
+func readProxyV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(header[:12], proxyV2Signature) {
+		return nil, nil, ErrNoProxyHeader
+	}
+	if header[12]>>4 != 2 {
+		return nil, nil, fmt.Errorf("unsupported PROXY protocol version: %d", header[12]>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+
+	switch header[12] & 0x0f {
+	case 0x0:
+		// LOCAL command, sent by the proxy on its own behalf.
+		return nil, nil, nil
+	case 0x1:
+	default:
+		return nil, nil, fmt.Errorf("unsupported PROXY protocol v2 command: %d", header[12]&0x0f)
+	}
+
+	switch header[13] >> 4 {
+	case 0x1:
+		if len(payload) < 12 {
+			return nil, nil, errors.New("invalid PROXY protocol v2 header, IPv4 addresses are truncated")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))},
+			&net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))},
+			nil
+	case 0x2:
+		if len(payload) < 36 {
+			return nil, nil, errors.New("invalid PROXY protocol v2 header, IPv6 addresses are truncated")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))},
+			&net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))},
+			nil
+	case 0x3:
+		if len(payload) < 216 {
+			return nil, nil, errors.New("invalid PROXY protocol v2 header, unix addresses are truncated")
+		}
+		return &net.UnixAddr{Net: NetworkUnix, Name: unixPath(payload[0:108])},
+			&net.UnixAddr{Net: NetworkUnix, Name: unixPath(payload[108:216])},
+			nil
+	default:
+		// Unspecified address family.
+		return nil, nil, nil
+	}
+}
+
+func unixPath(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
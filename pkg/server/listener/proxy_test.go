@@ -0,0 +1,338 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// proxyV2Header builds a PROXY protocol v2 header of the given command, family & payload.
+func proxyV2Header(command, family byte, payload []byte) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x20|command, family<<4|0x1)
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(payload)))
+	header = append(header, length...)
+	return append(header, payload...)
+}
+
+func ipv4Payload(src, dst string, srcPort, dstPort uint16) []byte {
+	payload := append([]byte{}, net.ParseIP(src).To4()...)
+	payload = append(payload, net.ParseIP(dst).To4()...)
+	ports := make([]byte, 4)
+	binary.BigEndian.PutUint16(ports[0:2], srcPort)
+	binary.BigEndian.PutUint16(ports[2:4], dstPort)
+	return append(payload, ports...)
+}
+
+func ipv6Payload(src, dst string, srcPort, dstPort uint16) []byte {
+	payload := append([]byte{}, net.ParseIP(src).To16()...)
+	payload = append(payload, net.ParseIP(dst).To16()...)
+	ports := make([]byte, 4)
+	binary.BigEndian.PutUint16(ports[0:2], srcPort)
+	binary.BigEndian.PutUint16(ports[2:4], dstPort)
+	return append(payload, ports...)
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	withTLV := append(ipv4Payload("192.0.2.1", "192.0.2.2", 56324, 443), 0x04, 0x00, 0x01, 0xff)
+	unix := make([]byte, 216)
+	copy(unix, "/run/client.sock")
+	copy(unix[108:], "/run/server.sock")
+
+	tests := []struct {
+		name    string
+		header  []byte
+		remote  string
+		local   string
+		wantErr string
+	}{
+		{
+			name:   "v1 tcp4",
+			header: []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			remote: "192.0.2.1:56324",
+			local:  "192.0.2.2:443",
+		},
+		{
+			name:   "v1 tcp6",
+			header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"),
+			remote: "[2001:db8::1]:56324",
+			local:  "[2001:db8::2]:443",
+		},
+		{
+			name:   "v1 unknown",
+			header: []byte("PROXY UNKNOWN\r\n"),
+		},
+		{
+			name:    "v1 without CRLF",
+			header:  []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\n"),
+			wantErr: "CRLF",
+		},
+		{
+			name:    "v1 truncated",
+			header:  []byte("PROXY TCP4 192.0.2.1"),
+			wantErr: "EOF",
+		},
+		{
+			name:    "v1 too long",
+			header:  []byte("PROXY TCP4 " + strings.Repeat("1", 100) + "\r\n"),
+			wantErr: "CRLF",
+		},
+		{
+			name:    "v1 missing fields",
+			header:  []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324\r\n"),
+			wantErr: "6 fields",
+		},
+		{
+			name:    "v1 invalid protocol",
+			header:  []byte("PROXY UDP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			wantErr: "unsupported",
+		},
+		{
+			name:    "v1 invalid address",
+			header:  []byte("PROXY TCP4 192.0.2 192.0.2.2 56324 443\r\n"),
+			wantErr: "invalid PROXY protocol v1 address",
+		},
+		{
+			name:    "v1 invalid port",
+			header:  []byte("PROXY TCP4 192.0.2.1 192.0.2.2 65536 443\r\n"),
+			wantErr: "invalid PROXY protocol v1 port",
+		},
+		{
+			name:    "v1 invalid signature",
+			header:  []byte("PROXIED TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			wantErr: ErrNoProxyHeader.Error(),
+		},
+		{
+			name:   "v2 ipv4",
+			header: proxyV2Header(0x1, 0x1, ipv4Payload("192.0.2.1", "192.0.2.2", 56324, 443)),
+			remote: "192.0.2.1:56324",
+			local:  "192.0.2.2:443",
+		},
+		{
+			name:   "v2 ipv4 with TLVs",
+			header: proxyV2Header(0x1, 0x1, withTLV),
+			remote: "192.0.2.1:56324",
+			local:  "192.0.2.2:443",
+		},
+		{
+			name:   "v2 ipv6",
+			header: proxyV2Header(0x1, 0x2, ipv6Payload("2001:db8::1", "2001:db8::2", 56324, 443)),
+			remote: "[2001:db8::1]:56324",
+			local:  "[2001:db8::2]:443",
+		},
+		{
+			name:   "v2 unix",
+			header: proxyV2Header(0x1, 0x3, unix),
+			remote: "/run/client.sock",
+			local:  "/run/server.sock",
+		},
+		{
+			name:   "v2 local",
+			header: proxyV2Header(0x0, 0x0, nil),
+		},
+		{
+			name:   "v2 unspecified family",
+			header: proxyV2Header(0x1, 0x0, nil),
+		},
+		{
+			name:    "v2 truncated header",
+			header:  proxyV2Signature[:10],
+			wantErr: "EOF",
+		},
+		{
+			name:    "v2 truncated payload",
+			header:  proxyV2Header(0x1, 0x1, ipv4Payload("192.0.2.1", "192.0.2.2", 56324, 443))[:20],
+			wantErr: "EOF",
+		},
+		{
+			name:    "v2 truncated addresses",
+			header:  proxyV2Header(0x1, 0x1, []byte{192, 0, 2, 1}),
+			wantErr: "truncated",
+		},
+		{
+			name:    "v2 invalid signature",
+			header:  append([]byte("\r\n\r\n\x00\r\nQUIZ\n"), 0x21, 0x11, 0x00, 0x00),
+			wantErr: ErrNoProxyHeader.Error(),
+		},
+		{
+			name:    "v2 invalid version",
+			header:  append(append([]byte{}, proxyV2Signature...), 0x11, 0x11, 0x00, 0x00),
+			wantErr: "unsupported PROXY protocol version",
+		},
+		{
+			name:    "v2 invalid command",
+			header:  proxyV2Header(0x2, 0x1, ipv4Payload("192.0.2.1", "192.0.2.2", 56324, 443)),
+			wantErr: "unsupported PROXY protocol v2 command",
+		},
+		{
+			name:    "no header",
+			header:  []byte("GET / HTTP/1.1\r\n"),
+			wantErr: ErrNoProxyHeader.Error(),
+		},
+		{
+			name:    "empty",
+			header:  []byte{},
+			wantErr: "EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		remote, local, err := readProxyHeader(bufio.NewReader(bytes.NewReader(tt.header)))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got := addrString(remote); got != tt.remote {
+			t.Errorf("%s: expected remote %q, got %q", tt.name, tt.remote, got)
+		}
+		if got := addrString(local); got != tt.local {
+			t.Errorf("%s: expected local %q, got %q", tt.name, tt.local, got)
+		}
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks([]string{"10.0.0.0/8", " 192.0.2.1 ", "2001:db8::1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"10.0.0.0/8", "192.0.2.1/32", "2001:db8::1/128"}
+	for i, network := range networks {
+		if network.String() != want[i] {
+			t.Errorf("expected %s, got %s", want[i], network)
+		}
+	}
+
+	for _, invalid := range []string{"10.0.0.0/33", "example.com"} {
+		if _, err := ParseNetworks([]string{invalid}); err == nil {
+			t.Errorf("%q: expected error", invalid)
+		}
+	}
+}
+
+// proxyServer accepts one connection of a PROXY protocol listener, returning its remote
+// address & the data read from it.
+func proxyServer(t *testing.T, config ProxyConfig) (string, <-chan string) {
+	t.Helper()
+
+	l, err := net.Listen(NetworkTCP, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed listening: %v", err)
+	}
+	l = NewProxyListener(l, config)
+	t.Cleanup(func() { _ = l.Close() })
+
+	results := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			results <- err.Error()
+			return
+		}
+		defer conn.Close()
+
+		data, err := ioutil.ReadAll(conn)
+		if err != nil {
+			results <- err.Error()
+			return
+		}
+		results <- addrString(conn.RemoteAddr())
+		results <- string(data)
+	}()
+
+	return l.Addr().String(), results
+}
+
+func send(t *testing.T, addr, data string) {
+	t.Helper()
+
+	conn, err := net.Dial(NetworkTCP, addr)
+	if err != nil {
+		t.Fatalf("failed connecting: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(data)); err != nil {
+		t.Fatalf("failed writing: %v", err)
+	}
+}
+
+func TestProxyListener(t *testing.T) {
+	addr, results := proxyServer(t, ProxyConfig{})
+
+	send(t, addr, "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\nhello")
+
+	if remote := <-results; remote != "192.0.2.1:56324" {
+		t.Fatalf("expected remote address from the header, got %q", remote)
+	}
+	if data := <-results; data != "hello" {
+		t.Fatalf("expected data following the header, got %q", data)
+	}
+}
+
+func TestProxyListenerWithoutHeader(t *testing.T) {
+	addr, results := proxyServer(t, ProxyConfig{})
+
+	send(t, addr, "hello")
+
+	if err := <-results; err != ErrNoProxyHeader.Error() {
+		t.Fatalf("expected ErrNoProxyHeader, got %q", err)
+	}
+}
+
+func TestProxyListenerUntrustedNetwork(t *testing.T) {
+	networks, err := ParseNetworks([]string{"192.0.2.0/24"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addr, results := proxyServer(t, ProxyConfig{TrustedNetworks: networks})
+
+	// Headers of untrusted clients are served as data, so they can't spoof addresses.
+	send(t, addr, "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n")
+
+	if remote := <-results; strings.HasPrefix(remote, "192.0.2.1") {
+		t.Fatalf("expected the actual remote address, got %q", remote)
+	}
+	if data := <-results; !strings.HasPrefix(data, "PROXY") {
+		t.Fatalf("expected header to be served as data, got %q", data)
+	}
+}
+
+func TestProxyListenerHeaderTimeout(t *testing.T) {
+	addr, results := proxyServer(t, ProxyConfig{HeaderTimeout: 50 * time.Millisecond})
+
+	conn, err := net.Dial(NetworkTCP, addr)
+	if err != nil {
+		t.Fatalf("failed connecting: %v", err)
+	}
+	defer conn.Close()
+
+	select {
+	case err := <-results:
+		if !strings.Contains(err, "timeout") {
+			t.Fatalf("expected header timeout, got %q", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected connection without header to time out")
+	}
+}
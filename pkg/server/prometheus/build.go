@@ -0,0 +1,29 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+	metricbackend "github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric/backend/opencensus"
+	"github.com/satriajidam/go-gin-skeleton/pkg/version"
+)
+
+// The build info recorder registers its metric globally, so it's only created once.
+var buildInfoOnce sync.Once
+
+// recordBuildInfo exports the build information of the running binary.
+func recordBuildInfo() {
+	buildInfoOnce.Do(func() {
+		info := version.Get()
+		metricbackend.NewBuildInfoRecorder(metric.BuildInfoRecorderConfig{}).RecordBuildInfo(
+			context.Background(),
+			metric.BuildProperty{
+				Version:   info.Version,
+				GitCommit: info.GitCommit,
+				BuildTime: info.BuildTime,
+				GoVersion: info.GoVersion,
+			},
+		)
+	})
+}
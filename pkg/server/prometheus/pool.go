@@ -0,0 +1,109 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+	metricbackend "github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric/backend/opencensus"
+)
+
+var (
+	// Pool recorders register their metrics globally, so they're only created once.
+	poolRecordersOnce sync.Once
+	dbPoolRecorder    metric.DBPoolRecorder
+	redisPoolRecorder metric.RedisPoolRecorder
+)
+
+// MonitorSQL registers SQL database connection(s), along with their read replicas,
+// whose connection pool stats are exported.
+func (s *Server) MonitorSQL(conns ...*sql.Connection) {
+	for _, c := range conns {
+		if c == nil {
+			continue
+		}
+		s.sqlConns = append(s.sqlConns, c)
+		s.sqlConns = append(s.sqlConns, c.Replicas()...)
+	}
+}
+
+// MonitorRedis registers Redis connection(s) whose connection pool stats are exported.
+func (s *Server) MonitorRedis(conns ...*redis.Connection) {
+	for _, c := range conns {
+		if c == nil {
+			continue
+		}
+		s.redisConns = append(s.redisConns, c)
+	}
+}
+
+func (s *Server) startPoolStats() {
+	if len(s.sqlConns) == 0 && len(s.redisConns) == 0 {
+		return
+	}
+
+	poolRecordersOnce.Do(func() {
+		dbPoolRecorder = metricbackend.NewDBPoolRecorder(metric.DBPoolRecorderConfig{})
+		redisPoolRecorder = metricbackend.NewRedisPoolRecorder(metric.RedisPoolRecorderConfig{})
+	})
+
+	interval := s.PoolStatsInterval
+	if interval <= 0 {
+		interval = DefaultPoolStatsInterval
+	}
+
+	s.stopPoolStats = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.recordPoolStats(context.Background())
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(s.stopPoolStats)
+}
+
+func (s *Server) recordPoolStats(ctx context.Context) {
+	for _, c := range s.sqlConns {
+		stats := c.DB.DB().Stats()
+		dbPoolRecorder.RecordDBPoolStats(
+			ctx,
+			metric.DBPoolProperty{Dialect: c.Dialect(), Host: c.Address()},
+			metric.DBPoolStats{
+				MaxOpenConnections: int64(stats.MaxOpenConnections),
+				OpenConnections:    int64(stats.OpenConnections),
+				InUse:              int64(stats.InUse),
+				Idle:               int64(stats.Idle),
+				WaitCount:          stats.WaitCount,
+				WaitDuration:       stats.WaitDuration,
+				MaxIdleClosed:      stats.MaxIdleClosed,
+				MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+			},
+		)
+	}
+
+	for _, c := range s.redisConns {
+		stats := c.Client.PoolStats()
+		redisPoolRecorder.RecordRedisPoolStats(
+			ctx,
+			metric.RedisPoolProperty{Host: c.Client.Options().Addr},
+			metric.RedisPoolStats{
+				Hits:       int64(stats.Hits),
+				Misses:     int64(stats.Misses),
+				Timeouts:   int64(stats.Timeouts),
+				TotalConns: int64(stats.TotalConns),
+				IdleConns:  int64(stats.IdleConns),
+				StaleConns: int64(stats.StaleConns),
+			},
+		)
+	}
+}
@@ -4,20 +4,42 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/satriajidam/go-gin-skeleton/pkg/cache/redis"
+	"github.com/satriajidam/go-gin-skeleton/pkg/database/sql"
 	"github.com/satriajidam/go-gin-skeleton/pkg/log"
+	grpcserver "github.com/satriajidam/go-gin-skeleton/pkg/server/grpc"
 	httpserver "github.com/satriajidam/go-gin-skeleton/pkg/server/http"
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
 	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
 	metricbackend "github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric/backend/opencensus"
 	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric/middleware"
 	ginmiddleware "github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric/middleware/gin"
+	grpcmiddleware "github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric/middleware/grpc"
 )
 
+// DefaultPoolStatsInterval is how often connection pool stats are exported by default.
+var DefaultPoolStatsInterval = 15 * time.Second
+
 // Server represents the implementation of Prometheus server object.
 type Server struct {
 	http *http.Server
 	Port string
 	Path string
+	// Listen is the listener spec of the server, see listener.ParseSpec. Defaults to the
+	// TCP port of the server.
+	Listen string
+	// ProxyProtocol reads the client addresses from PROXY protocol headers when set.
+	ProxyProtocol *listener.ProxyConfig
+	// PoolStatsInterval is how often the stats of monitored connection pools are exported.
+	PoolStatsInterval time.Duration
+	sqlConns          []*sql.Connection
+	redisConns        []*redis.Connection
+	stopPoolStats     chan struct{}
+	listener          listener.Managed
+	mu                sync.Mutex
 }
 
 // Target defines a target gin engine to monitor.
@@ -64,41 +86,71 @@ func NewServer(port, path string) *Server {
 	}
 
 	return &Server{
-		Port: port,
-		Path: path,
+		Port:              port,
+		Path:              path,
+		PoolStatsInterval: DefaultPoolStatsInterval,
 	}
 }
 
 // Start starts the HTTP server.
 func (s *Server) Start() error {
-	log.Info(fmt.Sprintf("Start Prometheus server on port %s", s.Port))
-
 	mux := http.NewServeMux()
 	handler, err := metricbackend.DefaultPrometheusExporter()
 	if err != nil {
 		return err
 	}
 
+	recordBuildInfo()
 	mux.Handle(s.Path, handler)
-	s.http = &http.Server{
-		Addr:    fmt.Sprintf(":%s", s.Port),
+
+	s.mu.Lock()
+	srv := &http.Server{
 		Handler: mux,
 	}
+	s.http = srv
+	s.mu.Unlock()
 
-	if err := s.http.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	l, err := s.listener.Listen(s.Listen, s.Port, s.ProxyProtocol)
+	if err != nil || l == nil {
 		return err
 	}
+	log.Info(fmt.Sprintf("Start Prometheus server on %s", listener.Addr(l)))
+
+	s.mu.Lock()
+	if !s.listener.Stopped() {
+		s.startPoolStats()
+	}
+	s.mu.Unlock()
+
+	return s.listener.Err(srv.Serve(l))
+}
 
-	return nil
+// Listening returns a channel which is closed once the server listens.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listener.Listening()
+}
+
+// Release stops accepting connections once the listener is handed off to another process,
+// while the accepted ones are still served until the server is stopped.
+func (s *Server) Release() error {
+	return s.listener.Release()
 }
 
 // Stop stops the HTTP server.
 func (s *Server) Stop(ctx context.Context) error {
 	log.Info(fmt.Sprintf("Stop Prometheus server on port %s", s.Port))
-	if err := s.http.Shutdown(ctx); err != nil {
-		return err
+	s.listener.Stop()
+	s.mu.Lock()
+	srv := s.http
+	if s.stopPoolStats != nil {
+		close(s.stopPoolStats)
+		s.stopPoolStats = nil
+	}
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
 	}
-	return nil
+	return srv.Shutdown(ctx)
 }
 
 // Monitor registers gin engine(s) to monitor.
@@ -116,3 +168,12 @@ func (s *Server) Monitor(targets ...*Target) {
 		t.HTTPServer.AddMiddleware(ginmiddleware.HTTPHandler(mdlw))
 	}
 }
+
+// MonitorGRPC registers gRPC server(s) to monitor.
+func (s *Server) MonitorGRPC(servers ...*grpcserver.Server) {
+	for _, srv := range servers {
+		recorder := metricbackend.NewGRPCRecorder(metric.GRPCRecorderConfig{})
+		srv.AddUnaryInterceptor(grpcmiddleware.UnaryServerInterceptor(recorder))
+		srv.AddStreamInterceptor(grpcmiddleware.StreamServerInterceptor(recorder))
+	}
+}
@@ -7,11 +7,9 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kelseyhightower/envconfig"
-	"github.com/satriajidam/go-gin-skeleton/pkg/log"
 )
 
 type config struct {
@@ -51,53 +49,23 @@ func init() {
 	}
 }
 
-// StartServers starts all given servers.
-func StartServers(servers ...Server) <-chan error {
-	serversCount := len(servers)
-	ch := make(chan error, serversCount)
+// NotifyContext returns a copy of the parent context which is cancelled when the process
+// receives an interrupt or termination signal. Calling the returned cancel function
+// stops relaying signals to the context.
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
 
-	for _, server := range servers {
-		go func(server Server) {
-			if err := server.Start(); err != nil {
-				ch <- err
-			}
-		}(server)
-	}
-
-	return ch
-}
-
-// StopServers stops all given servers.
-func StopServers(ctx context.Context, servers ...Server) {
-	log.Info("Shutting down all servers")
-	for _, server := range servers {
-		if err := server.Stop(ctx); err != nil {
-			log.Fatal(err, "Failed shutting down servers")
-		}
-	}
-	log.Info("All servers exited properly")
-}
-
-// RunServersGracefully runs all given servers in a graceful way.
-func RunServersGracefully(timeoutSeconds time.Duration, servers ...Server) {
-	if err := <-StartServers(servers...); err != nil {
-		panic(err)
-	}
-
-	// Graceful shutdown:
-	// - https://chenyitian.gitbooks.io/gin-web-framework/docs/38.html
-	// - https://medium.com/honestbee-tw-engineer/gracefully-shutdown-in-go-http-server-5f5e6b83da5a
-	// Wait for interrupt signal to gracefully shutdown the server.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
 
-	// Set graceful shutdown timeout to configured seconds.
-	ctx, cancel := context.WithTimeout(
-		context.Background(),
-		timeoutSeconds*time.Second,
-	)
-	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(quit)
+	}()
 
-	StopServers(ctx, servers...)
+	return ctx, cancel
 }
@@ -0,0 +1,26 @@
+// Package stoptimeout parses the stop timeouts of lifecycle components. It only depends on
+// the standard library, so configurations can be validated without importing the servers.
+package stoptimeout
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Parse parses stop timeouts of components formatted as "name=duration", e.g. "http=10s".
+func Parse(timeouts []string) (map[string]time.Duration, error) {
+	parsed := map[string]time.Duration{}
+	for _, t := range timeouts {
+		i := strings.Index(t, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid stop timeout %q, must be name=duration", t)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(t[i+1:]))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid stop timeout %q, duration must be positive", t)
+		}
+		parsed[strings.TrimSpace(t[:i])] = d
+	}
+	return parsed, nil
+}
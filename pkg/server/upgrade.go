@@ -0,0 +1,109 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/server/listener"
+)
+
+// EnvUpgradeReadyFD is the environment variable of the file descriptor which an upgraded
+// process writes to once it's started, see NotifyUpgradeReady.
+const EnvUpgradeReadyFD = "UPGRADE_READY_FD"
+
+// DefaultUpgradeTimeout is how long an upgraded process may take to start by default.
+var DefaultUpgradeTimeout = 30 * time.Second
+
+// Upgrade starts a new process of the current binary which inherits the listeners of the
+// servers, and waits until it's started. The new process is killed when it isn't started
+// within the timeout. Once it returns without error, the current process should stop.
+func Upgrade(timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultUpgradeTimeout
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	handoff, err := listener.NewHandoff()
+	if err != nil {
+		return err
+	}
+	defer handoff.Close()
+
+	ready, readyWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+
+	env := []string{}
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, EnvUpgradeReadyFD+"=") {
+			env = append(env, e)
+		}
+	}
+	env = append(env, handoff.Env...)
+	// Extra files start from file descriptor 3, so the pipe comes after the listeners.
+	env = append(env, fmt.Sprintf("%s=%d", EnvUpgradeReadyFD, 3+len(handoff.Files)))
+
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	files = append(files, handoff.Files...)
+	files = append(files, readyWriter)
+
+	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{Env: env, Files: files})
+	readyWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	started := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		if n, _ := ready.Read(b); n == 0 {
+			// The pipe is closed without a write when the process exits.
+			started <- errors.New("new process exited before it's started")
+			return
+		}
+		started <- nil
+	}()
+
+	select {
+	case err = <-started:
+	case <-time.After(timeout):
+		err = fmt.Errorf("new process isn't started within %s", timeout)
+	}
+
+	if err != nil {
+		_ = process.Kill()
+		_, _ = process.Wait()
+		return err
+	}
+
+	// The new process outlives this one, so it isn't waited for.
+	_ = process.Release()
+
+	return nil
+}
+
+// NotifyUpgradeReady tells the process which started the current one through Upgrade that
+// it's started. It does nothing when the current process isn't started through Upgrade.
+func NotifyUpgradeReady() error {
+	fd, err := strconv.Atoi(os.Getenv(EnvUpgradeReadyFD))
+	if err != nil {
+		return nil
+	}
+	os.Unsetenv(EnvUpgradeReadyFD)
+
+	ready := os.NewFile(uintptr(fd), "upgrade-ready")
+	defer ready.Close()
+
+	_, err = ready.Write([]byte{1})
+	return err
+}
@@ -0,0 +1,63 @@
+package opencensus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+type buildInfoRecorder struct {
+	// Tag keys.
+	versionKey   tag.Key
+	gitCommitKey tag.Key
+	buildTimeKey tag.Key
+	goVersionKey tag.Key
+
+	// Measurements.
+	buildInfo *stats.Int64Measure
+}
+
+// NewBuildInfoRecorder returns a new build info recorder with OpenCensus backend.
+func NewBuildInfoRecorder(cfg metric.BuildInfoRecorderConfig) metric.BuildInfoRecorder {
+	cfg.Defaults()
+
+	r := &buildInfoRecorder{}
+
+	newKey := func(name string) tag.Key {
+		key, err := tag.NewKey(name)
+		if err != nil {
+			panic(fmt.Errorf("failed initializing opencensus build info recorder tag keys: %v", err))
+		}
+		return key
+	}
+
+	r.versionKey = newKey(cfg.VersionLabel)
+	r.gitCommitKey = newKey(cfg.GitCommitLabel)
+	r.buildTimeKey = newKey(cfg.BuildTimeLabel)
+	r.goVersionKey = newKey(cfg.GoVersionLabel)
+
+	r.buildInfo = int64Gauge(metric.BuildInfo().Name, metric.BuildInfo().Description)
+
+	tagKeys := []tag.Key{r.versionKey, r.gitCommitKey, r.buildTimeKey, r.goVersionKey}
+
+	if err := view.Register(lastValueView(r.buildInfo, tagKeys)); err != nil {
+		panic(fmt.Errorf("failed registering opencensus build info recorder views: %v", err))
+	}
+
+	return r
+}
+
+func (r *buildInfoRecorder) RecordBuildInfo(ctx context.Context, prop metric.BuildProperty) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(r.versionKey, prop.Version),
+		tag.Upsert(r.gitCommitKey, prop.GitCommit),
+		tag.Upsert(r.buildTimeKey, prop.BuildTime),
+		tag.Upsert(r.goVersionKey, prop.GoVersion),
+	)
+	stats.Record(ctx, r.buildInfo.M(1))
+}
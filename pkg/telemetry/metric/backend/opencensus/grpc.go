@@ -0,0 +1,124 @@
+package opencensus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+type grpcRecorder struct {
+	// Tag keys.
+	serviceKey tag.Key
+	methodKey  tag.Key
+	codeKey    tag.Key
+
+	// Measurements.
+	requestDuration  *stats.Float64Measure
+	requestsTotal    *stats.Int64Measure
+	requestsInflight *stats.Int64Measure
+}
+
+// NewGRPCRecorder returns a new gRPC Recorder with OpenCensus backend.
+func NewGRPCRecorder(cfg metric.GRPCRecorderConfig) metric.GRPCRecorder {
+	cfg.Defaults()
+
+	r := &grpcRecorder{}
+
+	newKey := func(name string) tag.Key {
+		key, err := tag.NewKey(name)
+		if err != nil {
+			panic(fmt.Errorf("failed initializing opencensus grpc recorder tag keys: %v", err))
+		}
+		return key
+	}
+
+	r.serviceKey = newKey(cfg.ServiceLabel)
+	r.methodKey = newKey(cfg.MethodLabel)
+	r.codeKey = newKey(cfg.CodeLabel)
+
+	r.requestDuration = stats.Float64(
+		metric.GRPCRequestDuration().Name,
+		metric.GRPCRequestDuration().Description,
+		stats.UnitSeconds,
+	)
+	r.requestsTotal = stats.Int64(
+		metric.GRPCRequestsTotal().Name,
+		metric.GRPCRequestsTotal().Description,
+		stats.UnitDimensionless,
+	)
+	r.requestsInflight = stats.Int64(
+		metric.GRPCRequestsInflight().Name,
+		metric.GRPCRequestsInflight().Description,
+		stats.UnitDimensionless,
+	)
+
+	requestTagKeys := []tag.Key{r.serviceKey, r.methodKey, r.codeKey}
+	inflightTagKeys := []tag.Key{r.serviceKey, r.methodKey}
+
+	err := view.Register(
+		&view.View{
+			Name:        metric.GRPCRequestDuration().Name,
+			Description: metric.GRPCRequestDuration().Description,
+			TagKeys:     requestTagKeys,
+			Measure:     r.requestDuration,
+			Aggregation: view.Distribution(cfg.DurationBuckets...),
+		},
+		&view.View{
+			Name:        metric.GRPCRequestsTotal().Name,
+			Description: metric.GRPCRequestsTotal().Description,
+			TagKeys:     requestTagKeys,
+			Measure:     r.requestsTotal,
+			Aggregation: view.Sum(),
+		},
+		&view.View{
+			Name:        metric.GRPCRequestsInflight().Name,
+			Description: metric.GRPCRequestsInflight().Description,
+			TagKeys:     inflightTagKeys,
+			Measure:     r.requestsInflight,
+			Aggregation: view.Sum(),
+		},
+	)
+	if err != nil {
+		panic(fmt.Errorf("failed registering opencensus grpc recorder views: %v", err))
+	}
+
+	return r
+}
+
+func (r *grpcRecorder) RecordRequestDuration(
+	ctx context.Context, prop metric.GRPCRequestProperty, duration time.Duration,
+) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(r.serviceKey, prop.Service),
+		tag.Upsert(r.methodKey, prop.Method),
+		tag.Upsert(r.codeKey, prop.Code),
+	)
+	stats.Record(ctx, r.requestDuration.M(duration.Seconds()))
+}
+
+func (r *grpcRecorder) AddTotalRequests(
+	ctx context.Context, prop metric.GRPCRequestProperty, quantity int64,
+) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(r.serviceKey, prop.Service),
+		tag.Upsert(r.methodKey, prop.Method),
+		tag.Upsert(r.codeKey, prop.Code),
+	)
+	stats.Record(ctx, r.requestsTotal.M(quantity))
+}
+
+func (r *grpcRecorder) AddInflightRequests(
+	ctx context.Context, prop metric.GRPCInflightProperty, quantity int64,
+) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(r.serviceKey, prop.Service),
+		tag.Upsert(r.methodKey, prop.Method),
+	)
+	stats.Record(ctx, r.requestsInflight.M(quantity))
+}
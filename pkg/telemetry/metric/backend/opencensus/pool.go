@@ -0,0 +1,213 @@
+package opencensus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+type dbPoolRecorder struct {
+	// Tag keys.
+	dialectKey tag.Key
+	hostKey    tag.Key
+
+	// Measurements.
+	maxOpenConnections *stats.Int64Measure
+	openConnections    *stats.Int64Measure
+	inUseConnections   *stats.Int64Measure
+	idleConnections    *stats.Int64Measure
+	waitCount          *stats.Int64Measure
+	waitDuration       *stats.Float64Measure
+	maxIdleClosed      *stats.Int64Measure
+	maxLifetimeClosed  *stats.Int64Measure
+}
+
+// NewDBPoolRecorder returns a new SQL database pool recorder with OpenCensus backend.
+func NewDBPoolRecorder(cfg metric.DBPoolRecorderConfig) metric.DBPoolRecorder {
+	cfg.Defaults()
+
+	r := &dbPoolRecorder{}
+
+	dialectKey, err := tag.NewKey(cfg.DialectLabel)
+	if err != nil {
+		panic(fmt.Errorf("failed initializing opencensus db pool recorder tag keys: %v", err))
+	}
+	r.dialectKey = dialectKey
+
+	hostKey, err := tag.NewKey(cfg.HostLabel)
+	if err != nil {
+		panic(fmt.Errorf("failed initializing opencensus db pool recorder tag keys: %v", err))
+	}
+	r.hostKey = hostKey
+
+	r.maxOpenConnections = int64Gauge(
+		metric.DBPoolMaxOpenConnections().Name,
+		metric.DBPoolMaxOpenConnections().Description,
+	)
+	r.openConnections = int64Gauge(
+		metric.DBPoolOpenConnections().Name,
+		metric.DBPoolOpenConnections().Description,
+	)
+	r.inUseConnections = int64Gauge(
+		metric.DBPoolInUseConnections().Name,
+		metric.DBPoolInUseConnections().Description,
+	)
+	r.idleConnections = int64Gauge(
+		metric.DBPoolIdleConnections().Name,
+		metric.DBPoolIdleConnections().Description,
+	)
+	r.waitCount = int64Gauge(
+		metric.DBPoolWaitCount().Name,
+		metric.DBPoolWaitCount().Description,
+	)
+	r.waitDuration = stats.Float64(
+		metric.DBPoolWaitDuration().Name,
+		metric.DBPoolWaitDuration().Description,
+		stats.UnitSeconds,
+	)
+	r.maxIdleClosed = int64Gauge(
+		metric.DBPoolMaxIdleClosed().Name,
+		metric.DBPoolMaxIdleClosed().Description,
+	)
+	r.maxLifetimeClosed = int64Gauge(
+		metric.DBPoolMaxLifetimeClosed().Name,
+		metric.DBPoolMaxLifetimeClosed().Description,
+	)
+
+	tagKeys := []tag.Key{r.dialectKey, r.hostKey}
+
+	if err := view.Register(
+		lastValueView(r.maxOpenConnections, tagKeys),
+		lastValueView(r.openConnections, tagKeys),
+		lastValueView(r.inUseConnections, tagKeys),
+		lastValueView(r.idleConnections, tagKeys),
+		lastValueView(r.waitCount, tagKeys),
+		lastValueView(r.waitDuration, tagKeys),
+		lastValueView(r.maxIdleClosed, tagKeys),
+		lastValueView(r.maxLifetimeClosed, tagKeys),
+	); err != nil {
+		panic(fmt.Errorf("failed registering opencensus db pool recorder views: %v", err))
+	}
+
+	return r
+}
+
+func (r *dbPoolRecorder) RecordDBPoolStats(
+	ctx context.Context, prop metric.DBPoolProperty, s metric.DBPoolStats,
+) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(r.dialectKey, prop.Dialect),
+		tag.Upsert(r.hostKey, prop.Host),
+	)
+	stats.Record(ctx,
+		r.maxOpenConnections.M(s.MaxOpenConnections),
+		r.openConnections.M(s.OpenConnections),
+		r.inUseConnections.M(s.InUse),
+		r.idleConnections.M(s.Idle),
+		r.waitCount.M(s.WaitCount),
+		r.waitDuration.M(s.WaitDuration.Seconds()),
+		r.maxIdleClosed.M(s.MaxIdleClosed),
+		r.maxLifetimeClosed.M(s.MaxLifetimeClosed),
+	)
+}
+
+type redisPoolRecorder struct {
+	// Tag keys.
+	hostKey tag.Key
+
+	// Measurements.
+	hits             *stats.Int64Measure
+	misses           *stats.Int64Measure
+	timeouts         *stats.Int64Measure
+	totalConnections *stats.Int64Measure
+	idleConnections  *stats.Int64Measure
+	staleConnections *stats.Int64Measure
+}
+
+// NewRedisPoolRecorder returns a new Redis pool recorder with OpenCensus backend.
+func NewRedisPoolRecorder(cfg metric.RedisPoolRecorderConfig) metric.RedisPoolRecorder {
+	cfg.Defaults()
+
+	r := &redisPoolRecorder{}
+
+	hostKey, err := tag.NewKey(cfg.HostLabel)
+	if err != nil {
+		panic(fmt.Errorf("failed initializing opencensus redis pool recorder tag keys: %v", err))
+	}
+	r.hostKey = hostKey
+
+	r.hits = int64Gauge(
+		metric.RedisPoolHits().Name,
+		metric.RedisPoolHits().Description,
+	)
+	r.misses = int64Gauge(
+		metric.RedisPoolMisses().Name,
+		metric.RedisPoolMisses().Description,
+	)
+	r.timeouts = int64Gauge(
+		metric.RedisPoolTimeouts().Name,
+		metric.RedisPoolTimeouts().Description,
+	)
+	r.totalConnections = int64Gauge(
+		metric.RedisPoolTotalConnections().Name,
+		metric.RedisPoolTotalConnections().Description,
+	)
+	r.idleConnections = int64Gauge(
+		metric.RedisPoolIdleConnections().Name,
+		metric.RedisPoolIdleConnections().Description,
+	)
+	r.staleConnections = int64Gauge(
+		metric.RedisPoolStaleConnections().Name,
+		metric.RedisPoolStaleConnections().Description,
+	)
+
+	tagKeys := []tag.Key{r.hostKey}
+
+	if err := view.Register(
+		lastValueView(r.hits, tagKeys),
+		lastValueView(r.misses, tagKeys),
+		lastValueView(r.timeouts, tagKeys),
+		lastValueView(r.totalConnections, tagKeys),
+		lastValueView(r.idleConnections, tagKeys),
+		lastValueView(r.staleConnections, tagKeys),
+	); err != nil {
+		panic(fmt.Errorf("failed registering opencensus redis pool recorder views: %v", err))
+	}
+
+	return r
+}
+
+func (r *redisPoolRecorder) RecordRedisPoolStats(
+	ctx context.Context, prop metric.RedisPoolProperty, s metric.RedisPoolStats,
+) {
+	ctx, _ = tag.New(ctx, tag.Upsert(r.hostKey, prop.Host))
+	stats.Record(ctx,
+		r.hits.M(s.Hits),
+		r.misses.M(s.Misses),
+		r.timeouts.M(s.Timeouts),
+		r.totalConnections.M(s.TotalConns),
+		r.idleConnections.M(s.IdleConns),
+		r.staleConnections.M(s.StaleConns),
+	)
+}
+
+// int64Gauge creates a dimensionless measure for a gauge metric.
+func int64Gauge(name, description string) *stats.Int64Measure {
+	return stats.Int64(name, description, stats.UnitDimensionless)
+}
+
+// lastValueView creates a view exporting the last recorded value of a measure as a gauge.
+func lastValueView(m stats.Measure, tagKeys []tag.Key) *view.View {
+	return &view.View{
+		Name:        m.Name(),
+		Description: m.Description(),
+		TagKeys:     tagKeys,
+		Measure:     m,
+		Aggregation: view.LastValue(),
+	}
+}
@@ -0,0 +1,67 @@
+package opentelemetry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/unit"
+)
+
+// buildInfoRecorder keeps the recorded build information, which is reported by an
+// asynchronous observer whenever metrics are collected.
+type buildInfoRecorder struct {
+	// Label keys.
+	versionKey   label.Key
+	gitCommitKey label.Key
+	buildTimeKey label.Key
+	goVersionKey label.Key
+
+	mu    sync.RWMutex
+	props map[metric.BuildProperty]struct{}
+}
+
+// NewBuildInfoRecorder returns a new build info recorder with OpenTelemetry backend.
+func NewBuildInfoRecorder(cfg metric.BuildInfoRecorderConfig) metric.BuildInfoRecorder {
+	cfg.Defaults()
+
+	r := &buildInfoRecorder{
+		versionKey:   label.Key(cfg.VersionLabel),
+		gitCommitKey: label.Key(cfg.GitCommitLabel),
+		buildTimeKey: label.Key(cfg.BuildTimeLabel),
+		goVersionKey: label.Key(cfg.GoVersionLabel),
+		props:        map[metric.BuildProperty]struct{}{},
+	}
+
+	meter := otelmetric.Must(otel.Meter("build"))
+
+	meter.NewInt64ValueObserver(
+		metric.BuildInfo().Name,
+		func(_ context.Context, result otelmetric.Int64ObserverResult) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			for prop := range r.props {
+				result.Observe(1,
+					r.versionKey.String(prop.Version),
+					r.gitCommitKey.String(prop.GitCommit),
+					r.buildTimeKey.String(prop.BuildTime),
+					r.goVersionKey.String(prop.GoVersion),
+				)
+			}
+		},
+		otelmetric.WithDescription(metric.BuildInfo().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+
+	return r
+}
+
+func (r *buildInfoRecorder) RecordBuildInfo(ctx context.Context, prop metric.BuildProperty) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.props[prop] = struct{}{}
+}
@@ -0,0 +1,82 @@
+package opentelemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/unit"
+)
+
+type grpcRecorder struct {
+	// Label keys.
+	serviceKey label.Key
+	methodKey  label.Key
+	codeKey    label.Key
+
+	// Measurements.
+	requestDuration  otelmetric.Float64ValueRecorder
+	requestsTotal    otelmetric.Int64Counter
+	requestsInflight otelmetric.Int64UpDownCounter
+}
+
+// NewGRPCRecorder returns a new gRPC Recorder with OpenTelemetry backend.
+func NewGRPCRecorder(cfg metric.GRPCRecorderConfig) metric.GRPCRecorder {
+	cfg.Defaults()
+
+	meter := otelmetric.Must(otel.Meter("grpc"))
+
+	return &grpcRecorder{
+		serviceKey: label.Key(cfg.ServiceLabel),
+		methodKey:  label.Key(cfg.MethodLabel),
+		codeKey:    label.Key(cfg.CodeLabel),
+		requestDuration: meter.NewFloat64ValueRecorder(
+			metric.GRPCRequestDuration().Name,
+			otelmetric.WithDescription(metric.GRPCRequestDuration().Description),
+			otelmetric.WithUnit(unit.Unit("s")),
+		),
+		requestsTotal: meter.NewInt64Counter(
+			metric.GRPCRequestsTotal().Name,
+			otelmetric.WithDescription(metric.GRPCRequestsTotal().Description),
+			otelmetric.WithUnit(unit.Dimensionless),
+		),
+		requestsInflight: meter.NewInt64UpDownCounter(
+			metric.GRPCRequestsInflight().Name,
+			otelmetric.WithDescription(metric.GRPCRequestsInflight().Description),
+			otelmetric.WithUnit(unit.Dimensionless),
+		),
+	}
+}
+
+func (r *grpcRecorder) RecordRequestDuration(
+	ctx context.Context, prop metric.GRPCRequestProperty, duration time.Duration,
+) {
+	r.requestDuration.Record(ctx, duration.Seconds(),
+		r.serviceKey.String(prop.Service),
+		r.methodKey.String(prop.Method),
+		r.codeKey.String(prop.Code),
+	)
+}
+
+func (r *grpcRecorder) AddTotalRequests(
+	ctx context.Context, prop metric.GRPCRequestProperty, quantity int64,
+) {
+	r.requestsTotal.Add(ctx, quantity,
+		r.serviceKey.String(prop.Service),
+		r.methodKey.String(prop.Method),
+		r.codeKey.String(prop.Code),
+	)
+}
+
+func (r *grpcRecorder) AddInflightRequests(
+	ctx context.Context, prop metric.GRPCInflightProperty, quantity int64,
+) {
+	r.requestsInflight.Add(ctx, quantity,
+		r.serviceKey.String(prop.Service),
+		r.methodKey.String(prop.Method),
+	)
+}
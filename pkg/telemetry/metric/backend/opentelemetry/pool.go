@@ -0,0 +1,194 @@
+package opentelemetry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/unit"
+)
+
+// dbPoolRecorder keeps the latest stats of each database, which are reported by
+// asynchronous observers whenever metrics are collected.
+type dbPoolRecorder struct {
+	// Label keys.
+	dialectKey label.Key
+	hostKey    label.Key
+
+	mu    sync.RWMutex
+	stats map[metric.DBPoolProperty]metric.DBPoolStats
+}
+
+// NewDBPoolRecorder returns a new SQL database pool recorder with OpenTelemetry backend.
+func NewDBPoolRecorder(cfg metric.DBPoolRecorderConfig) metric.DBPoolRecorder {
+	cfg.Defaults()
+
+	r := &dbPoolRecorder{
+		dialectKey: label.Key(cfg.DialectLabel),
+		hostKey:    label.Key(cfg.HostLabel),
+		stats:      map[metric.DBPoolProperty]metric.DBPoolStats{},
+	}
+
+	meter := otelmetric.Must(otel.Meter("db"))
+
+	observe := func(value func(s metric.DBPoolStats) int64) otelmetric.Int64ObserverFunc {
+		return func(_ context.Context, result otelmetric.Int64ObserverResult) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			for prop, s := range r.stats {
+				result.Observe(value(s), r.dialectKey.String(prop.Dialect), r.hostKey.String(prop.Host))
+			}
+		}
+	}
+
+	meter.NewInt64ValueObserver(
+		metric.DBPoolMaxOpenConnections().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.MaxOpenConnections }),
+		otelmetric.WithDescription(metric.DBPoolMaxOpenConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64ValueObserver(
+		metric.DBPoolOpenConnections().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.OpenConnections }),
+		otelmetric.WithDescription(metric.DBPoolOpenConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64ValueObserver(
+		metric.DBPoolInUseConnections().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.InUse }),
+		otelmetric.WithDescription(metric.DBPoolInUseConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64ValueObserver(
+		metric.DBPoolIdleConnections().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.Idle }),
+		otelmetric.WithDescription(metric.DBPoolIdleConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64SumObserver(
+		metric.DBPoolWaitCount().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.WaitCount }),
+		otelmetric.WithDescription(metric.DBPoolWaitCount().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewFloat64SumObserver(
+		metric.DBPoolWaitDuration().Name,
+		func(_ context.Context, result otelmetric.Float64ObserverResult) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			for prop, s := range r.stats {
+				result.Observe(
+					s.WaitDuration.Seconds(),
+					r.dialectKey.String(prop.Dialect), r.hostKey.String(prop.Host),
+				)
+			}
+		},
+		otelmetric.WithDescription(metric.DBPoolWaitDuration().Description),
+		otelmetric.WithUnit(unit.Unit("s")),
+	)
+	meter.NewInt64SumObserver(
+		metric.DBPoolMaxIdleClosed().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.MaxIdleClosed }),
+		otelmetric.WithDescription(metric.DBPoolMaxIdleClosed().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64SumObserver(
+		metric.DBPoolMaxLifetimeClosed().Name,
+		observe(func(s metric.DBPoolStats) int64 { return s.MaxLifetimeClosed }),
+		otelmetric.WithDescription(metric.DBPoolMaxLifetimeClosed().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+
+	return r
+}
+
+func (r *dbPoolRecorder) RecordDBPoolStats(
+	ctx context.Context, prop metric.DBPoolProperty, s metric.DBPoolStats,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[prop] = s
+}
+
+// redisPoolRecorder keeps the latest stats of each Redis client, which are reported by
+// asynchronous observers whenever metrics are collected.
+type redisPoolRecorder struct {
+	// Label keys.
+	hostKey label.Key
+
+	mu    sync.RWMutex
+	stats map[metric.RedisPoolProperty]metric.RedisPoolStats
+}
+
+// NewRedisPoolRecorder returns a new Redis pool recorder with OpenTelemetry backend.
+func NewRedisPoolRecorder(cfg metric.RedisPoolRecorderConfig) metric.RedisPoolRecorder {
+	cfg.Defaults()
+
+	r := &redisPoolRecorder{
+		hostKey: label.Key(cfg.HostLabel),
+		stats:   map[metric.RedisPoolProperty]metric.RedisPoolStats{},
+	}
+
+	meter := otelmetric.Must(otel.Meter("redis"))
+
+	observe := func(value func(s metric.RedisPoolStats) int64) otelmetric.Int64ObserverFunc {
+		return func(_ context.Context, result otelmetric.Int64ObserverResult) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			for prop, s := range r.stats {
+				result.Observe(value(s), r.hostKey.String(prop.Host))
+			}
+		}
+	}
+
+	meter.NewInt64SumObserver(
+		metric.RedisPoolHits().Name,
+		observe(func(s metric.RedisPoolStats) int64 { return s.Hits }),
+		otelmetric.WithDescription(metric.RedisPoolHits().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64SumObserver(
+		metric.RedisPoolMisses().Name,
+		observe(func(s metric.RedisPoolStats) int64 { return s.Misses }),
+		otelmetric.WithDescription(metric.RedisPoolMisses().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64SumObserver(
+		metric.RedisPoolTimeouts().Name,
+		observe(func(s metric.RedisPoolStats) int64 { return s.Timeouts }),
+		otelmetric.WithDescription(metric.RedisPoolTimeouts().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64ValueObserver(
+		metric.RedisPoolTotalConnections().Name,
+		observe(func(s metric.RedisPoolStats) int64 { return s.TotalConns }),
+		otelmetric.WithDescription(metric.RedisPoolTotalConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64ValueObserver(
+		metric.RedisPoolIdleConnections().Name,
+		observe(func(s metric.RedisPoolStats) int64 { return s.IdleConns }),
+		otelmetric.WithDescription(metric.RedisPoolIdleConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+	meter.NewInt64SumObserver(
+		metric.RedisPoolStaleConnections().Name,
+		observe(func(s metric.RedisPoolStats) int64 { return s.StaleConns }),
+		otelmetric.WithDescription(metric.RedisPoolStaleConnections().Description),
+		otelmetric.WithUnit(unit.Dimensionless),
+	)
+
+	return r
+}
+
+func (r *redisPoolRecorder) RecordRedisPoolStats(
+	ctx context.Context, prop metric.RedisPoolProperty, s metric.RedisPoolStats,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[prop] = s
+}
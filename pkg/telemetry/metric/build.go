@@ -0,0 +1,53 @@
+package metric
+
+import "context"
+
+// BuildProperty stores the build information exported by the build info metric.
+type BuildProperty struct {
+	Version   string
+	GitCommit string
+	BuildTime string
+	GoVersion string
+}
+
+// BuildInfoRecorder records the build information of the running binary.
+// This interface has the required methods to be implemented by the metrics backend.
+type BuildInfoRecorder interface {
+	// RecordBuildInfo records the build information as labels of a gauge set to 1.
+	RecordBuildInfo(ctx context.Context, prop BuildProperty)
+}
+
+// BuildInfoRecorderConfig stores configurations for the build info metric recorder.
+type BuildInfoRecorderConfig struct {
+	VersionLabel   string
+	GitCommitLabel string
+	BuildTimeLabel string
+	GoVersionLabel string
+}
+
+// Defaults sets default values for build info metric recorder configurations.
+func (c *BuildInfoRecorderConfig) Defaults() {
+	if c.VersionLabel == "" {
+		c.VersionLabel = "version"
+	}
+
+	if c.GitCommitLabel == "" {
+		c.GitCommitLabel = "git_commit"
+	}
+
+	if c.BuildTimeLabel == "" {
+		c.BuildTimeLabel = "build_time"
+	}
+
+	if c.GoVersionLabel == "" {
+		c.GoVersionLabel = "go_version"
+	}
+}
+
+// BuildInfo returns build info metric metadata.
+func BuildInfo() metadata {
+	return metadata{
+		Name:        "build_info",
+		Description: "A metric with a constant '1' value labeled by the build information of the binary.",
+	}
+}
@@ -0,0 +1,81 @@
+package metric
+
+import (
+	"context"
+	"time"
+)
+
+// GRPCRequestProperty stores properties for the gRPC metrics of a handled call.
+type GRPCRequestProperty struct {
+	Service string
+	Method  string
+	Code    string
+}
+
+// GRPCInflightProperty stores properties for the gRPC metrics of an inflight call.
+type GRPCInflightProperty struct {
+	Service string
+	Method  string
+}
+
+// GRPCRecorder records and measures the gRPC server metrics.
+// This interface has the required methods to be implemented by the gRPC metrics backend.
+type GRPCRecorder interface {
+	// RecordRequestDuration measures the duration of a gRPC call.
+	RecordRequestDuration(ctx context.Context, prop GRPCRequestProperty, duration time.Duration)
+	// AddTotalRequests increments the total of handled calls.
+	AddTotalRequests(ctx context.Context, prop GRPCRequestProperty, quantity int64)
+	// AddInflightRequests increments and decrements the number of inflight calls.
+	AddInflightRequests(ctx context.Context, prop GRPCInflightProperty, quantity int64)
+}
+
+// GRPCRecorderConfig stores configurations for the gRPC metrics recorder.
+type GRPCRecorderConfig struct {
+	DurationBuckets []float64
+	ServiceLabel    string
+	MethodLabel     string
+	CodeLabel       string
+}
+
+// Defaults sets default values for gRPC metrics recorder configurations.
+func (c *GRPCRecorderConfig) Defaults() {
+	if len(c.DurationBuckets) == 0 {
+		c.DurationBuckets = durationBuckets
+	}
+
+	if c.ServiceLabel == "" {
+		c.ServiceLabel = "grpc_service"
+	}
+
+	if c.MethodLabel == "" {
+		c.MethodLabel = "grpc_method"
+	}
+
+	if c.CodeLabel == "" {
+		c.CodeLabel = "grpc_code"
+	}
+}
+
+// GRPCRequestDuration returns gRPC call duration metric metadata.
+func GRPCRequestDuration() metadata {
+	return metadata{
+		Name:        "grpc_server_handling_seconds",
+		Description: "The latency of the gRPC call in seconds.",
+	}
+}
+
+// GRPCRequestsTotal returns gRPC calls total metric metadata.
+func GRPCRequestsTotal() metadata {
+	return metadata{
+		Name:        "grpc_server_handled_total",
+		Description: "The total number of completed gRPC calls.",
+	}
+}
+
+// GRPCRequestsInflight returns gRPC calls inflight metric metadata.
+func GRPCRequestsInflight() metadata {
+	return metadata{
+		Name:        "grpc_server_inflight",
+		Description: "The number of inflight gRPC calls being processed at the same time.",
+	}
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/satriajidam/go-gin-skeleton/pkg/telemetry/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a gRPC interceptor reporting the metrics of unary calls.
+func UnaryServerInterceptor(recorder metric.GRPCRecorder) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		var resp interface{}
+		err := measure(ctx, recorder, info.FullMethod, func() error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor reporting the metrics of streaming
+// calls, measured from their start to their end.
+func StreamServerInterceptor(recorder metric.GRPCRecorder) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		return measure(ss.Context(), recorder, info.FullMethod, func() error {
+			return handler(srv, ss)
+		})
+	}
+}
+
+func measure(ctx context.Context, recorder metric.GRPCRecorder, fullMethod string, next func() error) error {
+	service, method := splitMethod(fullMethod)
+
+	inflight := metric.GRPCInflightProperty{Service: service, Method: method}
+	recorder.AddInflightRequests(ctx, inflight, 1)
+	defer recorder.AddInflightRequests(ctx, inflight, -1)
+
+	start := time.Now()
+	err := next()
+	duration := time.Since(start)
+
+	prop := metric.GRPCRequestProperty{
+		Service: service,
+		Method:  method,
+		Code:    status.Code(err).String(),
+	}
+	recorder.RecordRequestDuration(ctx, prop, duration)
+	recorder.AddTotalRequests(ctx, prop, 1)
+
+	return err
+}
+
+// splitMethod splits a full method name like /skeleton.v1.ProviderService/GetProvider
+// into its service & method names.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,194 @@
+package metric
+
+import (
+	"context"
+	"time"
+)
+
+// DBPoolProperty stores properties for the connection pool metrics of a SQL database.
+type DBPoolProperty struct {
+	Dialect string
+	Host    string
+}
+
+// DBPoolStats stores a snapshot of the connection pool statistics of a SQL database.
+type DBPoolStats struct {
+	MaxOpenConnections int64
+	OpenConnections    int64
+	InUse              int64
+	Idle               int64
+	WaitCount          int64
+	WaitDuration       time.Duration
+	MaxIdleClosed      int64
+	MaxLifetimeClosed  int64
+}
+
+// DBPoolRecorder records the connection pool metrics of SQL databases.
+// This interface has the required methods to be implemented by the metrics backend.
+type DBPoolRecorder interface {
+	// RecordDBPoolStats records the latest connection pool statistics of a SQL database.
+	RecordDBPoolStats(ctx context.Context, prop DBPoolProperty, stats DBPoolStats)
+}
+
+// DBPoolRecorderConfig stores configurations for the SQL database pool metrics recorder.
+type DBPoolRecorderConfig struct {
+	DialectLabel string
+	HostLabel    string
+}
+
+// Defaults sets default values for SQL database pool metrics recorder configurations.
+func (c *DBPoolRecorderConfig) Defaults() {
+	if c.DialectLabel == "" {
+		c.DialectLabel = "dialect"
+	}
+
+	if c.HostLabel == "" {
+		c.HostLabel = "host"
+	}
+}
+
+// RedisPoolProperty stores properties for the connection pool metrics of a Redis server.
+type RedisPoolProperty struct {
+	Host string
+}
+
+// RedisPoolStats stores a snapshot of the connection pool statistics of a Redis client.
+type RedisPoolStats struct {
+	Hits       int64
+	Misses     int64
+	Timeouts   int64
+	TotalConns int64
+	IdleConns  int64
+	StaleConns int64
+}
+
+// RedisPoolRecorder records the connection pool metrics of Redis clients.
+// This interface has the required methods to be implemented by the metrics backend.
+type RedisPoolRecorder interface {
+	// RecordRedisPoolStats records the latest connection pool statistics of a Redis client.
+	RecordRedisPoolStats(ctx context.Context, prop RedisPoolProperty, stats RedisPoolStats)
+}
+
+// RedisPoolRecorderConfig stores configurations for the Redis pool metrics recorder.
+type RedisPoolRecorderConfig struct {
+	HostLabel string
+}
+
+// Defaults sets default values for Redis pool metrics recorder configurations.
+func (c *RedisPoolRecorderConfig) Defaults() {
+	if c.HostLabel == "" {
+		c.HostLabel = "host"
+	}
+}
+
+// DBPoolMaxOpenConnections returns SQL database pool max open connections metric metadata.
+func DBPoolMaxOpenConnections() metadata {
+	return metadata{
+		Name:        "db_pool_max_open_connections",
+		Description: "The maximum number of open connections to the database.",
+	}
+}
+
+// DBPoolOpenConnections returns SQL database pool open connections metric metadata.
+func DBPoolOpenConnections() metadata {
+	return metadata{
+		Name:        "db_pool_open_connections",
+		Description: "The number of established connections both in use and idle.",
+	}
+}
+
+// DBPoolInUseConnections returns SQL database pool in use connections metric metadata.
+func DBPoolInUseConnections() metadata {
+	return metadata{
+		Name:        "db_pool_in_use_connections",
+		Description: "The number of connections currently in use.",
+	}
+}
+
+// DBPoolIdleConnections returns SQL database pool idle connections metric metadata.
+func DBPoolIdleConnections() metadata {
+	return metadata{
+		Name:        "db_pool_idle_connections",
+		Description: "The number of idle connections.",
+	}
+}
+
+// DBPoolWaitCount returns SQL database pool wait count metric metadata.
+func DBPoolWaitCount() metadata {
+	return metadata{
+		Name:        "db_pool_wait_count",
+		Description: "The total number of connections waited for.",
+	}
+}
+
+// DBPoolWaitDuration returns SQL database pool wait duration metric metadata.
+func DBPoolWaitDuration() metadata {
+	return metadata{
+		Name:        "db_pool_wait_duration_seconds",
+		Description: "The total time blocked waiting for a new connection in seconds.",
+	}
+}
+
+// DBPoolMaxIdleClosed returns SQL database pool max idle closed metric metadata.
+func DBPoolMaxIdleClosed() metadata {
+	return metadata{
+		Name:        "db_pool_max_idle_closed",
+		Description: "The total number of connections closed due to the maximum idle connections.",
+	}
+}
+
+// DBPoolMaxLifetimeClosed returns SQL database pool max lifetime closed metric metadata.
+func DBPoolMaxLifetimeClosed() metadata {
+	return metadata{
+		Name:        "db_pool_max_lifetime_closed",
+		Description: "The total number of connections closed due to the maximum connection lifetime.",
+	}
+}
+
+// RedisPoolHits returns Redis pool hits metric metadata.
+func RedisPoolHits() metadata {
+	return metadata{
+		Name:        "redis_pool_hits",
+		Description: "The total number of times a free connection was found in the pool.",
+	}
+}
+
+// RedisPoolMisses returns Redis pool misses metric metadata.
+func RedisPoolMisses() metadata {
+	return metadata{
+		Name:        "redis_pool_misses",
+		Description: "The total number of times a free connection was not found in the pool.",
+	}
+}
+
+// RedisPoolTimeouts returns Redis pool timeouts metric metadata.
+func RedisPoolTimeouts() metadata {
+	return metadata{
+		Name:        "redis_pool_timeouts",
+		Description: "The total number of times a wait for a connection timed out.",
+	}
+}
+
+// RedisPoolTotalConnections returns Redis pool total connections metric metadata.
+func RedisPoolTotalConnections() metadata {
+	return metadata{
+		Name:        "redis_pool_total_connections",
+		Description: "The number of connections in the pool.",
+	}
+}
+
+// RedisPoolIdleConnections returns Redis pool idle connections metric metadata.
+func RedisPoolIdleConnections() metadata {
+	return metadata{
+		Name:        "redis_pool_idle_connections",
+		Description: "The number of idle connections in the pool.",
+	}
+}
+
+// RedisPoolStaleConnections returns Redis pool stale connections metric metadata.
+func RedisPoolStaleConnections() metadata {
+	return metadata{
+		Name:        "redis_pool_stale_connections",
+		Description: "The total number of stale connections removed from the pool.",
+	}
+}
@@ -1,6 +1,10 @@
 package util
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strings"
+)
 
 func GetHostname() string {
 	hostname, err := os.Hostname()
@@ -9,3 +13,19 @@ func GetHostname() string {
 	}
 	return hostname
 }
+
+// FileStamp identifies the current versions of files by their modification times & sizes,
+// so changes can be detected by comparing stamps. Symbolic links are followed, like the
+// ones of Kubernetes config map & secret volumes. Empty paths & missing files are skipped.
+func FileStamp(paths ...string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+// Package version stores the build information of the binary. The variables are set at
+// build time through linker flags, e.g.:
+//
+//	go build -ldflags "-X github.com/satriajidam/go-gin-skeleton/pkg/version.Version=0.1.0"
+package version
+
+import "runtime"
+
+// Build information injected at build time.
+var (
+	// Version is the release version of the binary, read from build/<app>/.release.
+	Version = "dev"
+	// GitCommit is the git commit the binary is built from.
+	GitCommit = "unknown"
+	// BuildTime is when the binary is built, in RFC 3339 format.
+	BuildTime = "unknown"
+)
+
+// Info describes the build of the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}